@@ -12,14 +12,35 @@ import (
 	"os/exec"
 	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 type ComAppData struct {
-	AcceptInput bool
-	Active      bool
-	CmdLine     CmdLine
-	ComCfg      ComConfig
-	Fb          Feedback
+	AcceptInput    bool
+	Active         bool
+	Breadcrumbs    []string
+	CmdDocs        ScriptDocMap
+	CmdLine        CmdLine
+	ComCfg         ComConfig
+	Events         *Events
+	Fb             Feedback
+	FnDocs         ScriptDocMap
+	Keymaps        map[string]Keymap
+	KeysPending    []string
+	MacroRecord    []string
+	MacroRecording string
+	Macros         map[string][]string
+	MenuPath       string
+	Ruler          string
+	Scr            *Screen
+	cfgPath        string
+	keyBudget      int
+	macroAwait     int
+	macroDepth     int
+	macroFile      string
+	sourceDepth    int
+	templateCmds   map[string]*templateCmd
 }
 
 func NewComAppData(
@@ -28,9 +49,21 @@ func NewComAppData(
 	return ComAppData {
 		AcceptInput:   true,
 		Active:        true,
+		Breadcrumbs:   nil,
+		CmdDocs:       ScriptDocMap{},
 		CmdLine:       NewCmdLine(),
 		ComCfg:        ComConfigFromFile(customPath),
+		Events:        nil,
 		Fb:            "",
+		FnDocs:        ScriptDocMap{},
+		Keymaps:       map[string]Keymap{},
+		KeysPending:   nil,
+		Macros:        map[string][]string{},
+		MenuPath:      "",
+		Ruler:         "",
+		Scr:           NewScreen(),
+		cfgPath:       customPath,
+		templateCmds:  map[string]*templateCmd{},
 	}
 }
 
@@ -59,6 +92,7 @@ type (
 	ScriptCmd    func(cmd string) Feedback
 	ScriptFn     func()
 	ScriptCmdMap map[string]ScriptCmd
+	ScriptDocMap map[string]string
 	ScriptFnMap  map[string]ScriptFn
 )
 
@@ -66,6 +100,53 @@ const (
 	CmdlineMaxRows = 10
 )
 
+func (ad *ComAppData) AddComCmds(
+	cmdMap ScriptCmdMap,
+) {
+	ad.addKeymapCmds(cmdMap)
+	ad.addSourceCmd(cmdMap)
+	ad.addThemeCmd(cmdMap)
+}
+
+func (ad *ComAppData) AddConfigCmds(
+	cmdMap     ScriptCmdMap,
+	cmds       []CommandConfig,
+	pagerTitle string,
+) {
+	for _, v := range cmds {
+		var c = v
+
+		_, exists := cmdMap[c.Name]
+		if exists {
+			panic(fmt.Sprintf(`Command name "%v" in config is reserved`,
+				c.Name))
+		}
+
+		ad.CmdDocs[c.Name] = c.Help
+		cmdMap[c.Name] = func(cmd string) Feedback {
+			var (
+				args = strings.Fields(cmd)
+				fb   Feedback
+			)
+
+			switch c.Mode {
+			case "session":
+				return HandleShellSession(c.Shell, args...)
+
+			case "pager":
+				fb = HandleShell(c.Shell, args...)
+				if fb == "" {
+					return ""
+				}
+				return callPager(fb, ad.ComCfg.Pagers[0], pagerTitle)
+
+			default:
+				return HandleShell(c.Shell, args...)
+			}
+		}
+	}
+}
+
 func callPager(
 	fb Feedback,
 	pager pagerConfig,
@@ -88,7 +169,8 @@ func callPager(
 	tempFilePath = tempFile.Name()
 
 	tempFileContent = string(fb)
-	if tempFileContent[len(tempFileContent)-1] != '\n' {
+	if len(tempFileContent) == 0 ||
+		tempFileContent[len(tempFileContent)-1] != '\n' {
 		tempFileContent = fmt.Sprintf("%v\n", tempFileContent)
 	}
 
@@ -129,6 +211,9 @@ func handleCommand(
 	cmdLineParts = strings.SplitN(cmdLine.Content, " ", 2)
 	fn = customCmds[cmdLineParts[0]]
 	if fn != nil {
+		if len(cmdLineParts) < 2 {
+			return fn("")
+		}
 		return fn(cmdLineParts[1])
 	}
 
@@ -173,6 +258,11 @@ func HandleKeyCmdline(
 	cursor           *int,
 	fb               *Feedback,
 ) {
+	var (
+		r    rune
+		size int
+	)
+
 	switch key {
 	case comCfg.Keys.Cmdenter:
 		*fb = handleCommand(active,
@@ -185,19 +275,23 @@ func HandleKeyCmdline(
 		fallthrough
 	case csi.SigTstp:
 		*cmdLine = NewCmdLine()
-		fmt.Printf(csi.CursorHide)
+		csi.Send(csi.CursorHide)
 
 	case csi.Backspace:
 		if cmdLine.Cursor > 0 {
+			_, size = utf8.DecodeLastRuneInString(
+				cmdLine.Content[:cmdLine.Cursor])
 			cmdLine.Content =
-				(cmdLine.Content)[:cmdLine.Cursor-1] +
+				(cmdLine.Content)[:cmdLine.Cursor-size] +
 				(cmdLine.Content)[cmdLine.Cursor:]
-			cmdLine.Cursor--
+			cmdLine.Cursor -= size
 		}
 
 	case csi.CursorRight:
 		if cmdLine.Cursor < len(cmdLine.Content) {
-			cmdLine.Cursor++
+			_, size = utf8.DecodeRuneInString(
+				cmdLine.Content[cmdLine.Cursor:])
+			cmdLine.Cursor += size
 		}
 
 	case csi.CursorUp:
@@ -211,7 +305,9 @@ func HandleKeyCmdline(
 
 	case csi.CursorLeft:
 		if cmdLine.Cursor > 0 {
-			cmdLine.Cursor--
+			_, size = utf8.DecodeLastRuneInString(
+				cmdLine.Content[:cmdLine.Cursor])
+			cmdLine.Cursor -= size
 		}
 
 	case csi.CursorDown:
@@ -233,33 +329,38 @@ func HandleKeyCmdline(
 
 	case csi.Delete:
 		if cmdLine.Cursor < len(cmdLine.Content) {
+			_, size = utf8.DecodeRuneInString(
+				cmdLine.Content[cmdLine.Cursor:])
 			cmdLine.Content =
 				(cmdLine.Content)[:cmdLine.Cursor] +
-				(cmdLine.Content)[cmdLine.Cursor+1:]
+				(cmdLine.Content)[cmdLine.Cursor+size:]
 		}
 
 	case csi.End:
 		cmdLine.Cursor = len(cmdLine.Content)
 
 	default:
-		if len(key) == 1 {
+		r, size = utf8.DecodeRuneInString(key)
+		if size == len(key) && unicode.IsPrint(r) {
 			var insertReplace = 0
 
 			if cmdLine.Insert == true &&
 				cmdLine.Cursor < len(cmdLine.Content) {
-				insertReplace = 1
+				_, insertReplace = utf8.DecodeRuneInString(
+					cmdLine.Content[cmdLine.Cursor:])
 			}
 
 			cmdLine.Content = (cmdLine.Content)[:cmdLine.Cursor] +
 				key +
 				(cmdLine.Content)[cmdLine.Cursor+insertReplace:]
-			cmdLine.Cursor++
+			cmdLine.Cursor += len(key)
 		}
 	}
 }
 
 func HandleShell(
 	shell string,
+	args  ...string,
 ) Feedback {
 	var cmd *exec.Cmd
 	var cmderr io.ReadCloser
@@ -268,7 +369,7 @@ func HandleShell(
 	var strerr []byte
 	var strout []byte
 
-	cmd = exec.Command("sh", "-c", shell)
+	cmd = shellCommand(shell, args)
 
 	cmderr, err = cmd.StderrPipe()
 	if err != nil {
@@ -310,13 +411,14 @@ func HandleShell(
 
 func HandleShellSession(
 	shell string,
+	args  ...string,
 ) Feedback {
 	var cmd *exec.Cmd
 	var cmderr io.ReadCloser
 	var err error
 	var strerr []byte
 
-	cmd = exec.Command("sh", "-c", shell)
+	cmd = shellCommand(shell, args)
 	cmd.Stdout = os.Stdout
 	cmd.Stdin = os.Stdin
 
@@ -325,6 +427,9 @@ func HandleShellSession(
 		return Feedback(fmt.Sprintf("Could not get stderr: %s", err))
 	}
 
+	suspendTerm()
+	defer resumeTerm()
+
 	err = cmd.Start()
 	if err != nil {
 		return Feedback(
@@ -336,11 +441,6 @@ func HandleShellSession(
 		return Feedback(fmt.Sprintf("Could not read stderr: %s", err))
 	}
 
-	fmt.Printf("%v%v\n", csi.FgDefault, csi.BgDefault)
-	fmt.Printf(csi.CursorShow)
-	defer fmt.Printf(csi.CursorHide)
-	defer fmt.Printf("%v", csi.Clear)
-
 	err = cmd.Wait()
 	if err != nil {
 		return Feedback(fmt.Sprintf("Child error: %s", err))
@@ -352,3 +452,14 @@ func HandleShellSession(
 
 	return ""
 }
+
+func shellCommand(
+	shell string,
+	args  []string,
+) *exec.Cmd {
+	if len(args) == 0 {
+		return exec.Command("sh", "-c", shell)
+	}
+
+	return exec.Command("sh", append([]string{"-c", shell, "sh"}, args...)...)
+}
@@ -16,24 +16,37 @@ import (
 	"strings"
 )
 
+// Source is "title" for the first line of each menu's title,
+// or "name" for the menu names.
+type breadcrumbConfig struct {
+	RegionConfig
+	Source    string
+	Separator string
+}
+
 type cmdlineConfig struct {
-	Alignment string
-	Prefix    string
-	Fg        csi.FgColor
-	Bg        csi.BgColor
+	RegionConfig
+	Prefix string
+}
+
+type CommandConfig struct {
+	Name  string
+	Shell string
+	Mode  string
+	Help  string
 }
 
 type feedbackConfig struct {
-	Alignment string
-	Prefix    string
-	Fg        csi.FgColor
-	Bg        csi.BgColor
+	RegionConfig
+	Prefix     string
+	Wrap       string
+	WrapIndent int
 }
 
 type headerConfig struct {
-	Alignment string
-	Fg        csi.FgColor
-	Bg        csi.BgColor
+	RegionConfig
+	Wrap       string
+	WrapIndent int
 }
 
 type pagerConfig struct {
@@ -43,28 +56,81 @@ type pagerConfig struct {
 }
 
 type keysConfig struct {
-	Left     string
-	Down     string
-	Up       string
-	Right    string
-	Quit     string
-	Cmdmode  string
-	Cmdenter string
+	Left     string `help:"go back"`
+	Down     string `help:"go down"`
+	Up       string `help:"go up"`
+	Right    string `help:"go into"`
+	Quit     string `help:"quit the program"`
+	Cmdmode  string `help:"enter the internal command line"`
+	Cmdenter string `help:"run the internal command line"`
+	Record   string `help:"record keys into the following register, stop recording"`
+	Play     string `help:"play the keys recorded in the following register"`
+}
+
+// RegionConfig is embedded in the config of each region,
+// its fields are written next to the other fields of that region.
+type RegionConfig struct {
+	Alignment      string
+	BlockAlignment string
+	Border         string
+	BorderTitle    string
+	Margin         Spacing
+	Padding        Spacing
+	Fg             csi.FgColor
+	Bg             csi.BgColor
+	Attrs          csi.Attrs
+}
+
+func (c RegionConfig) Region(
+) Region {
+	return Region{
+		Alignment:      c.Alignment,
+		BlockAlignment: c.BlockAlignment,
+		Border:         c.Border,
+		BorderTitle:    c.BorderTitle,
+		Margin:         c.Margin,
+		Padding:        c.Padding,
+		Style:          csi.Style{Fg: c.Fg, Bg: c.Bg, Attrs: c.Attrs},
+	}
+}
+
+// Validate checks all but the alignment,
+// which some regions only need when they are shown.
+func (c RegionConfig) Validate(
+) {
+	ValidateBlockAlignment(c.BlockAlignment)
+	ValidateBorder(c.Border)
+	ValidateSpacing(c.Margin)
+	ValidateSpacing(c.Padding)
+}
+
+type statusConfig struct {
+	RegionConfig
+	Text       string
+	Wrap       string
+	WrapIndent int
 }
 
 type titleConfig struct {
-	Alignment string
-	Fg        csi.FgColor
-	Bg        csi.BgColor
+	RegionConfig
+	Wrap       string
+	WrapIndent int
 }
 
 type ComConfig struct {
-	Pagers   []pagerConfig
-	Keys     keysConfig
-	Header   headerConfig
-	Title    titleConfig
-	CmdLine  cmdlineConfig
-	Feedback feedbackConfig
+	Commands   []CommandConfig
+	Pagers     []pagerConfig
+	Keys       keysConfig
+	Header     headerConfig
+	Title      titleConfig
+	CmdLine    cmdlineConfig
+	Feedback   feedbackConfig
+	Status     statusConfig
+	Breadcrumb breadcrumbConfig
+	Scroll     scrollConfig
+	Layout     layoutConfig
+	Theme      string
+	Template   templateConfig
 }
 
 func AnyConfigFromFile(
@@ -72,16 +138,94 @@ func AnyConfigFromFile(
 	cfgFileName string,
 	customPath string,
 ) {
+	var (
+		err   error
+		f     *os.File
+		found bool
+	)
+
+	f, found = OpenConfigFile(cfgFileName, customPath)
+	if found == false {
+		panic("No config file could be found")
+	}
+	defer f.Close()
+
+	str, err := io.ReadAll(f)
+	if err != nil {
+		panic(fmt.Sprintf("Config file \"%v\" could not be read:\n%v",
+			f.Name(), err))
+	}
+
+	err = json.Unmarshal(str, cfg)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func ComConfigFromFile(
+	customPath string,
+) ComConfig {
+	var (
+		fb  Feedback
+		ret ComConfig
+	)
+
+	AnyConfigFromFile(&ret, "common.json", customPath)
+	ret.Layout.validate()
+	ret.Scroll.validate()
+	ret.validateAlignments()
+	ret.validateWraps()
+	ret.validateRegions()
+	ValidateCommands(ret.Commands)
+	ret.validatePagers()
+	ret.Breadcrumb.validate()
+
+	if ret.Theme != "" {
+		fb = applyTheme(ret.Theme, customPath)
+		if fb != "" {
+			panic(string(fb))
+		}
+	}
+
+	return ret
+}
+
+func ValidateAlignment(
+	alignment string,
+) {
+	switch alignment {
+	case "left":
+	case "center":
+	case "centered":
+	case "right":
+
+	default:
+		panic(fmt.Sprintf(`Unknown alignment "%v" in config`, alignment))
+	}
+}
+
+// ValidateBlockAlignment allows an empty alignment,
+// which aligns each line on its own.
+func ValidateBlockAlignment(
+	alignment string,
+) {
+	if alignment != "" {
+		ValidateAlignment(alignment)
+	}
+}
+
+func OpenConfigFile(
+	cfgFileName string,
+	customPath string,
+) (*os.File, bool) {
 	type path struct {
 		EnvVar string
 		Core   string
 	}
 
 	var curPath string
-	var i int
 	var err error
 	var f *os.File
-	var found bool = false
 	var paths = []path{
 		path{"", customPath},
 		path{"", "/etc/hui/"},
@@ -110,7 +254,6 @@ func AnyConfigFromFile(
 		}
 
 		f, err = os.Open(curPath)
-		defer f.Close()
 
 		if errors.Is(err, os.ErrNotExist) {
 			continue
@@ -119,50 +262,94 @@ func AnyConfigFromFile(
 				"Config file \"%v\" could not be opened:\n%v\n",
 				curPath, err)
 		} else {
-			found = true
-			break
+			return f, true
 		}
 	}
 
-	if found == false {
-		panic("No config file could be found")
-	}
+	return nil, false
+}
 
-	str, err := io.ReadAll(f)
-	if err != nil {
-		panic(fmt.Sprintf("Config file \"%v\" could not be read:\n%v",
-			paths[i], err))
-	}
+// Built-in commands that config commands can not replace.
+// Names made of digits are reserved for line numbers.
+var reservedCmds = []string{
+	"exit",
+	"help",
+	"map",
+	"q",
+	"quit",
+	"sh",
+	"shs",
+	"source",
+	"theme",
+	"unmap",
+}
 
-	err = json.Unmarshal(str, cfg)
-	if err != nil {
-		panic(err)
+// MergeCommands returns the commands of common.json and of the app's config,
+// the app's replacing those of the same name.
+func MergeCommands(
+	comCmds []CommandConfig,
+	appCmds []CommandConfig,
+) []CommandConfig {
+	var ret []CommandConfig
+
+	for _, v := range comCmds {
+		if slices.ContainsFunc(appCmds, func(c CommandConfig) bool {
+			return c.Name == v.Name
+		}) == false {
+			ret = append(ret, v)
+		}
 	}
+
+	return append(ret, appCmds...)
 }
 
-func ComConfigFromFile(
-	customPath string,
-) ComConfig {
-	var ret ComConfig
+func ValidateCommands(
+	cmds []CommandConfig,
+) {
+	var names []string
 
-	AnyConfigFromFile(&ret, "common.json", customPath)
-	ret.validateAlignments()
-	ret.validatePagers()
+	for _, v := range cmds {
+		if v.Name == "" || strings.ContainsAny(v.Name, " \t") {
+			panic(fmt.Sprintf(`Invalid command name "%v" in config`,
+				v.Name))
+		}
 
-	return ret
+		if slices.Contains(reservedCmds, v.Name) ||
+			strings.Trim(v.Name, "0123456789") == "" {
+			panic(fmt.Sprintf(`Command name "%v" in config is reserved`,
+				v.Name))
+		}
+
+		if slices.Contains(names, v.Name) {
+			panic(fmt.Sprintf(`Command "%v" is defined more than once in config`,
+				v.Name))
+		}
+		names = append(names, v.Name)
+
+		switch v.Mode {
+		case "":
+		case "feedback":
+		case "session":
+		case "pager":
+
+		default:
+			panic(fmt.Sprintf(`Unknown mode "%v" of command "%v" in config`,
+				v.Mode,
+				v.Name))
+		}
+	}
 }
 
-func ValidateAlignment(
-	alignment string,
+func (c breadcrumbConfig) validate(
 ) {
-	switch alignment {
-	case "left":
-	case "center":
-	case "centered":
-	case "right":
+	switch c.Source {
+	case "":
+	case "title":
+	case "name":
 
 	default:
-		panic(fmt.Sprintf(`Unknown alignment "%v" in config`, alignment))
+		panic(fmt.Sprintf(`Unknown breadcrumb source "%v" in config`,
+			c.Source))
 	}
 }
 
@@ -172,6 +359,34 @@ func (c ComConfig) validateAlignments(
 	ValidateAlignment(c.Title.Alignment)
 	ValidateAlignment(c.CmdLine.Alignment)
 	ValidateAlignment(c.Feedback.Alignment)
+	if c.Layout.uses("status") {
+		ValidateAlignment(c.Status.Alignment)
+	}
+	if c.Layout.uses("breadcrumb") {
+		ValidateAlignment(c.Breadcrumb.Alignment)
+	}
+}
+
+func (c ComConfig) validateRegions(
+) {
+	for _, v := range []RegionConfig{
+		c.Header.RegionConfig,
+		c.Title.RegionConfig,
+		c.CmdLine.RegionConfig,
+		c.Feedback.RegionConfig,
+		c.Status.RegionConfig,
+		c.Breadcrumb.RegionConfig,
+	} {
+		v.Validate()
+	}
+}
+
+func (c ComConfig) validateWraps(
+) {
+	ValidateWrap(c.Header.Wrap, c.Header.WrapIndent)
+	ValidateWrap(c.Title.Wrap, c.Title.WrapIndent)
+	ValidateWrap(c.Feedback.Wrap, c.Feedback.WrapIndent)
+	ValidateWrap(c.Status.Wrap, c.Status.WrapIndent)
 }
 
 func (c *ComConfig) validatePagers(
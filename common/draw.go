@@ -4,74 +4,203 @@
 package common
 
 import (
+	"github.com/SchokiCoder/gohui/csi"
+
 	"fmt"
 	"strings"
 )
 
-func DrawUpper(
-	comCfg ComConfig,
-	header []string,
-	termW int,
-	title []string,
+// PrintLower prints the cmdline or feedback as plain lines,
+// for what is left after the terminal is restored.
+func (ad *ComAppData) PrintLower(
 ) {
-	for _, v := range header {
-		Cprinta(comCfg.Header.Alignment,
-			comCfg.Header.Fg,
-			comCfg.Header.Bg,
-			termW,
-			v)
-	}
+	switch {
+	case ad.CmdLine.Active:
+		fmt.Printf("%v%v\n", ad.ComCfg.CmdLine.Prefix, ad.CmdLine.Content)
 
-	for _, v := range title {
-		Cprinta(comCfg.Title.Alignment,
-			comCfg.Title.Fg,
-			comCfg.Title.Bg,
-			termW,
-			v)
+	case ad.Fb != "":
+		fmt.Printf("%v%v\n", ad.ComCfg.Feedback.Prefix, ad.Fb)
 	}
 }
 
-func GenerateLower(
-	cmdline    string,
-	cmdMode    bool,
+// DrawLower draws the cmdline or feedback,
+// with the ruler right of the feedback,
+// and returns the number of rows used.
+func DrawLower(
+	scr        *Screen,
+	cmdLine    CmdLine,
 	comCfg     ComConfig,
 	fb         *Feedback,
 	pagerTitle string,
-	termW      int,
-) string {
+	ruler      string,
+) int {
 	var (
 		fits bool
-		ret  string
+		r    Region
+		str  string
+		y    int
 	)
 
-	if cmdMode == true {
-		ret = Csprintfa(comCfg.CmdLine.Alignment,
-			comCfg.CmdLine.Fg,
-			comCfg.CmdLine.Bg,
-			termW,
-			"%v%v",
-			comCfg.CmdLine.Prefix,
-			cmdline)
+	r = lowerRegion(cmdLine, comCfg)
+	if cmdLine.Active == true {
+		str = fmt.Sprintf("%v%v", comCfg.CmdLine.Prefix, cmdLine.Content)
 	} else {
-		ret, fits = tryFitFeedback(*fb, comCfg.Feedback.Prefix, termW)
+		str, fits = tryFitFeedback(*fb,
+			comCfg.Feedback.Prefix,
+			comCfg.Feedback.Wrap,
+			r.InnerWidth(scr.W))
 		if fits == false {
-			ret = string(callPager(*fb, comCfg.Pagers[0], pagerTitle))
+			str = string(callPager(*fb, comCfg.Pagers[0], pagerTitle))
 			*fb = ""
-			ret, _ = tryFitFeedback(
-				Feedback(ret),
+			str, _ = tryFitFeedback(
+				Feedback(str),
 				comCfg.Feedback.Prefix,
-				termW)
+				comCfg.Feedback.Wrap,
+				r.InnerWidth(scr.W))
 		}
+	}
+
+	y = scr.H - r.Height(1)
+	scr.PrintRegion(r, y, []string{str}, nil)
+
+	if cmdLine.Active == false && ruler != "" {
+		scr.Print(scr.W -
+				r.Margin.Right -
+				r.borderWidth() -
+				r.Padding.Right -
+				TextWidth(ruler),
+			y + r.Margin.Top + r.borderWidth() + r.Padding.Top,
+			comCfg.Scroll.Style(),
+			ruler)
+	}
+
+	scr.SetCursor(r.LineX(scr.W, str) +
+			TextWidth(comCfg.CmdLine.Prefix) +
+			TextWidth(cmdLine.Content[:cmdLine.Cursor]) + 1,
+		y + r.Margin.Top + r.borderWidth() + r.Padding.Top + 1)
+
+	return r.Height(1)
+}
+
+func lowerRegion(
+	cmdLine CmdLine,
+	comCfg  ComConfig,
+) Region {
+	if cmdLine.Active == true {
+		return comCfg.CmdLine.Region()
+	}
+
+	return comCfg.Feedback.Region()
+}
+
+func (ad *ComAppData) drawText(
+	scr    *Screen,
+	region string,
+	header string,
+	title  string,
+) {
+	var (
+		lines []string
+		r     Region
+	)
+
+	r, lines = ad.textLines(region, scr.W, header, title)
+	scr.PrintRegion(r, 0, lines, nil)
+}
+
+func (ad *ComAppData) textHeight(
+	region string,
+	w      int,
+	header string,
+	title  string,
+) int {
+	var (
+		lines []string
+		r     Region
+	)
+
+	r, lines = ad.textLines(region, w, header, title)
+
+	return r.Height(len(lines))
+}
+
+// textLines returns the region and wrapped lines
+// of the header, title, status or breadcrumb.
+func (ad *ComAppData) textLines(
+	region string,
+	w      int,
+	header string,
+	title  string,
+) (Region, []string) {
+	var (
+		indent int
+		r      Region
+		text   string
+		wrap   string
+	)
+
+	switch region {
+	case "header":
+		r = ad.ComCfg.Header.Region()
+		text = header
+		wrap = ad.ComCfg.Header.Wrap
+		indent = ad.ComCfg.Header.WrapIndent
+
+	case "title":
+		r = ad.ComCfg.Title.Region()
+		text = title
+		wrap = ad.ComCfg.Title.Wrap
+		indent = ad.ComCfg.Title.WrapIndent
+
+	case "status":
+		r = ad.ComCfg.Status.Region()
+		text = ad.ComCfg.Status.Text
+		wrap = ad.ComCfg.Status.Wrap
+		indent = ad.ComCfg.Status.WrapIndent
 
-		ret = Csprintfa(comCfg.Feedback.Alignment,
-			comCfg.Feedback.Fg,
-			comCfg.Feedback.Bg,
-			termW,
-			"%v",
-			ret)
+	case "breadcrumb":
+		r = ad.ComCfg.Breadcrumb.Region()
+		return r, []string{ad.breadcrumbText(r.InnerWidth(w), r.Style)}
 	}
 
-	return ret
+	return r, WrapText(r.InnerWidth(w),
+		csi.Markup(ad.expandTemplate(text), r.Style),
+		wrap,
+		indent)
+}
+
+// breadcrumbText joins the breadcrumbs to fit into w,
+// leaving out crumbs from the left and then cutting the last one.
+// Each crumb is expanded and marked up like the title.
+func (ad *ComAppData) breadcrumbText(
+	w     int,
+	style csi.Style,
+) string {
+	var (
+		crumbs = make([]string, len(ad.Breadcrumbs))
+		ret    string
+		sep    = ad.ComCfg.Breadcrumb.Separator
+	)
+
+	if len(crumbs) == 0 {
+		return ""
+	}
+
+	for i, v := range ad.Breadcrumbs {
+		crumbs[i] = csi.Markup(ad.expandTemplate(v), style)
+	}
+
+	for i := range crumbs {
+		ret = strings.Join(crumbs[i:], sep)
+		if i > 0 {
+			ret = Ellipsis + sep + ret
+		}
+		if TextWidth(ret) <= w {
+			return ret
+		}
+	}
+
+	return TruncateLeft(w, crumbs[len(crumbs) - 1])
 }
 
 func PrintAbout(
@@ -107,33 +236,51 @@ func SplitByLines(
 ) []string {
 	var step1 []string
 	var step2 []string
-	var lastCut int
+	var esc bool
+	var line strings.Builder
+	var lineLen int
+	var n int
+	var width int
 
 	step1 = strings.Split(str, "\n")
 
 	for _, v := range step1 {
-		if len(v) <= maxLineLen {
+		if TextWidth(v) <= maxLineLen {
 			step2 = append(step2, v)
 			continue
 		}
 
-		lastCut = 0
-		for len(v[lastCut:]) > maxLineLen {
-			step2 = append(step2, v[lastCut:lastCut+maxLineLen])
-			lastCut += maxLineLen
+		line.Reset()
+		lineLen = 0
+		for i := 0; i < len(v); i += n {
+			n, width, esc = clusterAt(v[i:], lineLen)
+
+			if esc == false && lineLen + width > maxLineLen && lineLen > 0 {
+				step2 = append(step2, line.String())
+				line.Reset()
+				lineLen = 0
+				n, width, _ = clusterAt(v[i:], lineLen)
+			}
+
+			line.WriteString(v[i:i+n])
+			lineLen += width
 		}
-		step2 = append(step2, v[lastCut:])
+		step2 = append(step2, line.String())
 	}
 
 	return step2
 }
 
+// In WrapNone mode, feedback always fits,
+// as it is cut down to its first line instead of going to the pager.
 func tryFitFeedback(
 	fb       Feedback,
 	fbPrefix string,
+	wrap     string,
 	termW    int,
 ) (string, bool) {
 	var (
+		lines   []string
 		retStr  string
 		retFits bool
 	)
@@ -141,6 +288,11 @@ func tryFitFeedback(
 	retStr = strings.TrimSpace(string(fb))
 	retStr = fmt.Sprintf("%v%v", fbPrefix, retStr)
 
+	if wrap == WrapNone {
+		lines = strings.Split(retStr, "\n")
+		return Truncate(termW, lines[0], len(lines) > 1), true
+	}
+
 	if len(SplitByLines(termW, retStr)) > 1 {
 		retStr = fbPrefix
 		retFits = false
@@ -0,0 +1,181 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+// Copyright (C) 2024 - 2025  Andy Frank Schoknecht
+
+package common
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Exactly one of the fields is set.
+// Done is the completion of a background job, run on the main loop.
+type Event struct {
+	Done   func()
+	Key    string
+	Resize bool
+	Tick   bool
+}
+
+// Keys are only read from stdin when asked for via Wait,
+// so that child processes can have stdin to themselves.
+type Events struct {
+	c          chan Event
+	keyReq     chan bool
+	reading    bool
+	ticker     *time.Ticker
+	tickerStop chan bool
+}
+
+func NewEvents(
+) *Events {
+	var (
+		ret = &Events{
+			c:          make(chan Event, 16),
+			keyReq:     make(chan bool),
+			reading:    false,
+			ticker:     nil,
+			tickerStop: nil,
+		}
+		winch = make(chan os.Signal, 1)
+	)
+
+	go ret.readKeys()
+
+	signal.Notify(winch, syscall.SIGWINCH)
+	go func() {
+		for range winch {
+			ret.trySend(Event{Resize: true})
+		}
+	}()
+
+	return ret
+}
+
+func (ad *ComAppData) Run(
+	draw      func(),
+	handleKey func(key string),
+) {
+	for ad.Active {
+		ad.RunOnce(draw, handleKey)
+	}
+}
+
+func (ad *ComAppData) RunOnce(
+	draw      func(),
+	handleKey func(key string),
+) {
+	var ev Event
+
+	draw()
+
+	if ad.AcceptInput == false && ad.Active == false {
+		return
+	}
+
+	ev = ad.Events.Wait(ad.AcceptInput)
+	switch {
+	case ev.Done != nil:
+		ev.Done()
+
+	case ev.Key != "":
+		if ad.AcceptInput {
+			ad.HandleKeyInput(ev.Key, handleKey)
+		}
+	}
+}
+
+func (e *Events) Job(
+	work func() func(),
+) {
+	go func() {
+		defer RecoverTerm()
+
+		e.c <- Event{Done: work()}
+	}()
+}
+
+func (e *Events) SetTicker(
+	interval time.Duration,
+) {
+	var (
+		stop chan bool
+		t    *time.Ticker
+	)
+
+	if e.ticker != nil {
+		e.ticker.Stop()
+		close(e.tickerStop)
+		e.ticker = nil
+	}
+
+	if interval <= 0 {
+		return
+	}
+
+	stop = make(chan bool)
+	t = time.NewTicker(interval)
+	e.ticker = t
+	e.tickerStop = stop
+	go func() {
+		for {
+			select {
+			case <-t.C:
+				e.trySend(Event{Tick: true})
+
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+func (e *Events) Wait(
+	keys bool,
+) Event {
+	var ev Event
+
+	if keys && e.reading == false {
+		e.keyReq <- true
+		e.reading = true
+	}
+
+	ev = <-e.c
+	if ev.Key != "" {
+		e.reading = false
+	}
+
+	return ev
+}
+
+func (e *Events) readKeys(
+) {
+	var (
+		err         error
+		rawInput    = make([]byte, 4)
+		rawInputLen int
+	)
+
+	defer RecoverTerm()
+
+	for range e.keyReq {
+		rawInputLen, err = os.Stdin.Read(rawInput)
+		if err != nil {
+			panic(fmt.Sprintf("Reading from stdin failed:\n%v", err))
+		}
+
+		e.c <- Event{Key: string(rawInput[0:rawInputLen])}
+	}
+}
+
+func (e *Events) trySend(
+	ev Event,
+) {
+	select {
+	case e.c <- ev:
+	default:
+	}
+}
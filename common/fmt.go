@@ -86,6 +86,10 @@ func Csprintf(
 	var ret string
 
 	ret = fmt.Sprintf(format, a...)
+	if csi.ColorDepth == csi.ColorsNone {
+		return ret
+	}
+
 	ret = fmt.Sprintf("%v%v%v%v%v",
 		fg, bg, ret, csi.FgDefault, csi.BgDefault)
 
@@ -106,7 +110,7 @@ func Csprintfa(
 	)
 
 	str = fmt.Sprintf(format, a...)
-	strlen = len(str)
+	strlen = TextWidth(str)
 	str = Csprintf(fg, bg, "%v", str)
 
 	switch alignment {
@@ -0,0 +1,285 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+// Copyright (C) 2024 - 2025  Andy Frank Schoknecht
+
+package common
+
+import (
+	"github.com/SchokiCoder/gohui/csi"
+
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const helpColor = `color, "#rrggbb", "#rgb", a name like "red", ` +
+	`an index, a role like "accent" or "default"`
+
+// types that are written in configs other than their kind suggests
+var helpConfigTypes = map[reflect.Type]string{
+	reflect.TypeOf(csi.Attrs(0)): `list of "bold", "dim", "italic", ` +
+		`"underline", "reverse" or "strikethrough"`,
+	reflect.TypeOf(csi.Color{}):   helpColor,
+	reflect.TypeOf(csi.FgColor{}): helpColor,
+	reflect.TypeOf(csi.BgColor{}): helpColor,
+}
+
+const helpTopics = `Help topics:
+
+    :help keys
+    :help commands
+    :help functions
+    :help config
+`
+
+func (ad *ComAppData) AddHelpCmd(
+	cmdMap     ScriptCmdMap,
+	fnMap      ScriptFnMap,
+	appHelp    string,
+	appCfg     interface{},
+	appCfgFile string,
+	pagerTitle string,
+) {
+	ad.CmdDocs["help"] = "opens this help, optionally only the given topic"
+	cmdMap["help"] = func(cmd string) Feedback {
+		var (
+			found bool
+			help  string
+		)
+
+		help, found = generateHelp(strings.TrimSpace(cmd),
+			appHelp,
+			ad.ComCfg,
+			appCfg,
+			appCfgFile,
+			cmdMap,
+			ad.CmdDocs,
+			fnMap,
+			ad.FnDocs)
+		if found == false {
+			return Feedback(fmt.Sprintf(`Help topic "%v" not found`,
+				strings.TrimSpace(cmd)))
+		}
+
+		return callPager(Feedback(help), ad.ComCfg.Pagers[0], pagerTitle)
+	}
+}
+
+func generateHelp(
+	topic      string,
+	appHelp    string,
+	comCfg     ComConfig,
+	appCfg     interface{},
+	appCfgFile string,
+	cmdMap     ScriptCmdMap,
+	cmdDocs    ScriptDocMap,
+	fnMap      ScriptFnMap,
+	fnDocs     ScriptDocMap,
+) (string, bool) {
+	var (
+		keys    = helpKeys(comCfg, appCfg)
+		cmds    = helpCommands(cmdMap, cmdDocs)
+		fns     = helpFunctions(fnMap, fnDocs)
+		cfgs    = helpConfig(comCfg, appCfg, appCfgFile)
+	)
+
+	switch topic {
+	case "":
+		return fmt.Sprintf("%v\n%v\n%v\n%v\n%v\n%v",
+			appHelp, keys, cmds, fns, cfgs, helpTopics), true
+
+	case "keys":
+		return keys, true
+
+	case "commands":
+		return cmds, true
+
+	case "functions":
+		return fns, true
+
+	case "config":
+		return cfgs, true
+	}
+
+	return "", false
+}
+
+func helpCommands(
+	cmdMap  ScriptCmdMap,
+	cmdDocs ScriptDocMap,
+) string {
+	var (
+		names []string
+		ret   strings.Builder
+	)
+
+	ret.WriteString(`Registered commands:
+
+    q quit exit
+        quit the program
+
+    *number*
+        when given a positive number, it is used as a line number to scroll to
+`)
+
+	for k := range cmdMap {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	for _, v := range names {
+		fmt.Fprintf(&ret, "\n    %v\n        %v\n", v, helpDoc(cmdDocs[v]))
+	}
+
+	return ret.String()
+}
+
+func helpConfig(
+	comCfg     ComConfig,
+	appCfg     interface{},
+	appCfgFile string,
+) string {
+	var ret strings.Builder
+
+	ret.WriteString("Config reference:\n\n    common.json\n")
+	helpConfigFields(&ret, reflect.TypeOf(comCfg), "")
+
+	if appCfg != nil {
+		fmt.Fprintf(&ret, "\n    %v\n", appCfgFile)
+		helpConfigFields(&ret, reflect.TypeOf(appCfg), "")
+	}
+
+	return ret.String()
+}
+
+func helpConfigFields(
+	b      *strings.Builder,
+	t      reflect.Type,
+	prefix string,
+) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		var (
+			f     = t.Field(i)
+			found bool
+			name  = prefix + f.Name
+			ft    = f.Type
+			typ   string
+		)
+
+		if f.IsExported() == false {
+			continue
+		}
+
+		for {
+			if ft.Kind() == reflect.Slice {
+				name += "[]"
+				ft = ft.Elem()
+			} else if ft.Kind() == reflect.Map {
+				name += "{}"
+				ft = ft.Elem()
+			} else {
+				break
+			}
+		}
+
+		typ, found = helpConfigTypes[ft]
+		switch {
+		case f.Anonymous:
+			helpConfigFields(b, ft, prefix)
+
+		case found:
+			fmt.Fprintf(b, "        %-40v %v\n", name, typ)
+
+		case ft.Kind() == reflect.Struct:
+			helpConfigFields(b, ft, name + ".")
+
+		default:
+			fmt.Fprintf(b, "        %-40v %v\n", name, ft.Kind())
+		}
+	}
+}
+
+func helpDoc(
+	doc string,
+) string {
+	if doc == "" {
+		return "(undocumented)"
+	}
+
+	return doc
+}
+
+func helpFunctions(
+	fnMap  ScriptFnMap,
+	fnDocs ScriptDocMap,
+) string {
+	var (
+		names []string
+		ret   strings.Builder
+	)
+
+	ret.WriteString("Go functions:\n")
+
+	for k := range fnMap {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	for _, v := range names {
+		fmt.Fprintf(&ret, "\n    %v\n        %v\n", v, helpDoc(fnDocs[v]))
+	}
+
+	return ret.String()
+}
+
+func helpKeys(
+	comCfg ComConfig,
+	appCfg interface{},
+) string {
+	var ret strings.Builder
+
+	ret.WriteString("Keybindings, written as for :map:\n")
+	helpKeysFields(&ret, reflect.ValueOf(comCfg.Keys))
+
+	if appCfg != nil {
+		var v = reflect.Indirect(reflect.ValueOf(appCfg))
+
+		v = v.FieldByName("Keys")
+		if v.IsValid() {
+			helpKeysFields(&ret, v)
+		}
+	}
+
+	ret.WriteString(`
+    <Up> <Down> <Left> <Right> <PageUp> <PageDown> <Home> <End>
+        navigate and edit the internal command line
+
+    <C-c> <C-d>
+        leave the internal command line or quit the program
+`)
+
+	return ret.String()
+}
+
+func helpKeysFields(
+	b *strings.Builder,
+	v reflect.Value,
+) {
+	var t = v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		var doc = t.Field(i).Tag.Get("help")
+
+		if doc == "" {
+			doc = t.Field(i).Name
+		}
+
+		fmt.Fprintf(b, "\n    %v\n        %v\n",
+			KeysToNotation([]string{v.Field(i).String()}),
+			doc)
+	}
+}
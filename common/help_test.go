@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+// Copyright (C) 2024 - 2025  Andy Frank Schoknecht
+
+package common
+
+import (
+	"github.com/SchokiCoder/gohui/csi"
+
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHelpConfigFields(
+	t *testing.T,
+) {
+	var (
+		b     strings.Builder
+		lines = map[string]string{}
+		parts []string
+		want  = map[string]string{
+			"Style.Attrs": `list of "bold"`,
+			"Style.Fg":    `color, "#rrggbb"`,
+			"Style.Bg":    `color, "#rrggbb"`,
+			"Name":        "string",
+		}
+	)
+
+	helpConfigFields(&b, reflect.TypeOf(struct {
+		Name  string
+		Style struct {
+			Fg    csi.FgColor
+			Bg    csi.BgColor
+			Attrs csi.Attrs
+		}
+	}{}), "")
+
+	for _, v := range strings.Split(strings.TrimSpace(b.String()), "\n") {
+		parts = strings.SplitN(strings.TrimSpace(v), " ", 2)
+		lines[parts[0]] = strings.TrimSpace(parts[1])
+	}
+
+	for k, v := range want {
+		if strings.HasPrefix(lines[k], v) == false {
+			t.Errorf("%v is listed as %q, want %q", k, lines[k], v)
+		}
+	}
+
+	if len(lines) != len(want) {
+		t.Errorf("got fields %v", lines)
+	}
+}
+
+func TestHelpKeysNotation(
+	t *testing.T,
+) {
+	var (
+		cfg  ComConfig
+		help string
+	)
+
+	cfg.Keys.Cmdenter = "\r"
+	cfg.Keys.Quit = "\x03"
+
+	help = helpKeys(cfg, nil)
+	for _, v := range []string{"<CR>", "<C-c>"} {
+		if strings.Contains(help, "\n    " + v + "\n") == false {
+			t.Errorf("%v is not listed:\n%v", v, help)
+		}
+	}
+
+	if strings.Join(ParseKeys("<CR><C-c>"), "") != "\r\x03" {
+		t.Errorf("listed keys are not read back by :map")
+	}
+}
@@ -0,0 +1,260 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+// Copyright (C) 2024 - 2025  Andy Frank Schoknecht
+
+package common
+
+import (
+	"github.com/SchokiCoder/gohui/csi"
+
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+type Keymap struct {
+	Lhs []string
+	Rhs []string
+}
+
+// KeyBudget is how many keys one pressed key may expand to in total.
+const (
+	KeymapMaxDepth = 100
+	KeyBudget      = 10000
+)
+
+var keyNotations = []struct {
+	Name string
+	Key  string
+}{
+	{"CR", "\r"},
+	{"Enter", "\r"},
+	{"Return", "\r"},
+	{"Esc", "\x1b"},
+	{"Space", " "},
+	{"Tab", "\t"},
+	{"BS", csi.Backspace},
+	{"lt", "<"},
+	{"Up", csi.CursorUp},
+	{"Down", csi.CursorDown},
+	{"Left", csi.CursorLeft},
+	{"Right", csi.CursorRight},
+	{"Home", csi.Home},
+	{"End", csi.End},
+	{"Insert", csi.Insert},
+	{"Del", csi.Delete},
+	{"PageUp", csi.PgUp},
+	{"PageDown", csi.PgDown},
+}
+
+func (ad *ComAppData) HandleKeyMapped(
+	key    string,
+	handle func(key string),
+) {
+	ad.keyBudget = KeyBudget
+	ad.handleKeyMapped(key, handle, 0)
+}
+
+func (ad *ComAppData) handleKeyMapped(
+	key    string,
+	handle func(key string),
+	depth  int,
+) {
+	var (
+		first   string
+		joined  string
+		km      Keymap
+		mapped  bool
+		pending []string
+	)
+
+	if ad.CmdLine.Active || ad.Active == false {
+		handle(key)
+		return
+	}
+
+	if depth > KeymapMaxDepth || ad.spendKey() == false {
+		ad.KeysPending = nil
+		ad.Fb = "Key mapping recursion limit reached"
+		return
+	}
+
+	ad.KeysPending = append(ad.KeysPending, key)
+	joined = strings.Join(ad.KeysPending, "")
+
+	km, mapped = ad.Keymaps[joined]
+	if mapped {
+		ad.KeysPending = nil
+		for _, v := range km.Rhs {
+			ad.handleKeyMapped(v, handle, depth + 1)
+		}
+		return
+	}
+
+	for _, v := range ad.Keymaps {
+		if len(v.Lhs) > len(ad.KeysPending) &&
+			slices.Equal(v.Lhs[:len(ad.KeysPending)], ad.KeysPending) {
+			return
+		}
+	}
+
+	first = ad.KeysPending[0]
+	pending = ad.KeysPending[1:]
+	ad.KeysPending = nil
+
+	handle(first)
+	for _, v := range pending {
+		ad.handleKeyMapped(v, handle, depth)
+	}
+}
+
+// spendKey takes a key from the budget of the pressed key,
+// and reports whether there was one left.
+func (ad *ComAppData) spendKey(
+) bool {
+	if ad.keyBudget <= 0 {
+		return false
+	}
+
+	ad.keyBudget--
+	return true
+}
+
+func KeysToNotation(
+	keys []string,
+) string {
+	var ret strings.Builder
+
+keyLoop:
+	for _, k := range keys {
+		for _, v := range keyNotations {
+			if v.Key == k {
+				fmt.Fprintf(&ret, "<%v>", v.Name)
+				continue keyLoop
+			}
+		}
+
+		if len(k) == 1 && k[0] < ' ' {
+			fmt.Fprintf(&ret, "<C-%c>", k[0] + '`')
+			continue
+		}
+
+		ret.WriteString(k)
+	}
+
+	return ret.String()
+}
+
+func ParseKeys(
+	notation string,
+) []string {
+	var (
+		end  int
+		name string
+		ret  []string
+		rest = notation
+	)
+
+restLoop:
+	for len(rest) > 0 {
+		end = strings.IndexByte(rest, '>')
+
+		if rest[0] == '<' && end > 0 {
+			name = rest[1:end]
+
+			for _, v := range keyNotations {
+				if strings.EqualFold(v.Name, name) {
+					ret = append(ret, v.Key)
+					rest = rest[end+1:]
+					continue restLoop
+				}
+			}
+
+			name = strings.ToLower(name)
+			if len(name) == 3 && strings.HasPrefix(name, "c-") &&
+				name[2] >= 'a' && name[2] <= 'z' {
+				ret = append(ret, string(rune(name[2] - 'a' + 1)))
+				rest = rest[end+1:]
+				continue
+			}
+		}
+
+		ret = append(ret, rest[:1])
+		rest = rest[1:]
+	}
+
+	return ret
+}
+
+func (ad *ComAppData) addKeymapCmds(
+	cmdMap ScriptCmdMap,
+) {
+	ad.CmdDocs["map"] = "maps keys to a sequence, lists all mappings without args"
+	cmdMap["map"] = func(cmd string) Feedback {
+		var (
+			args  = strings.SplitN(strings.TrimSpace(cmd), " ", 2)
+			lhs   []string
+			km    Keymap
+			found bool
+		)
+
+		if args[0] == "" {
+			return ad.listKeymaps()
+		}
+
+		lhs = ParseKeys(args[0])
+		if len(args) < 2 {
+			km, found = ad.Keymaps[strings.Join(lhs, "")]
+			if found == false {
+				return Feedback(fmt.Sprintf(`No mapping for "%v"`,
+					args[0]))
+			}
+			return Feedback(fmt.Sprintf("%v %v",
+				KeysToNotation(km.Lhs),
+				KeysToNotation(km.Rhs)))
+		}
+
+		ad.Keymaps[strings.Join(lhs, "")] = Keymap{
+			Lhs: lhs,
+			Rhs: ParseKeys(strings.TrimSpace(args[1])),
+		}
+		return ""
+	}
+
+	ad.CmdDocs["unmap"] = "removes the mapping of the given keys"
+	cmdMap["unmap"] = func(cmd string) Feedback {
+		var (
+			found bool
+			lhs   = strings.Join(ParseKeys(strings.TrimSpace(cmd)), "")
+		)
+
+		_, found = ad.Keymaps[lhs]
+		if found == false {
+			return Feedback(fmt.Sprintf(`No mapping for "%v"`,
+				strings.TrimSpace(cmd)))
+		}
+
+		delete(ad.Keymaps, lhs)
+		return ""
+	}
+}
+
+func (ad *ComAppData) listKeymaps(
+) Feedback {
+	var (
+		lines []string
+	)
+
+	if len(ad.Keymaps) == 0 {
+		return "No key mappings"
+	}
+
+	for _, v := range ad.Keymaps {
+		lines = append(lines, fmt.Sprintf("%v %v",
+			KeysToNotation(v.Lhs),
+			KeysToNotation(v.Rhs)))
+	}
+	sort.Strings(lines)
+
+	return Feedback(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,58 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+// Copyright (C) 2024 - 2025  Andy Frank Schoknecht
+
+package common
+
+import (
+	"testing"
+	"time"
+)
+
+// newTestAppData returns app data without any config files.
+func newTestAppData(
+) *ComAppData {
+	return &ComAppData{
+		Active:  true,
+		CmdDocs: ScriptDocMap{},
+		CmdLine: NewCmdLine(),
+		Keymaps: map[string]Keymap{},
+		Macros:  map[string][]string{},
+	}
+}
+
+func TestKeymapSelfExpanding(
+	t *testing.T,
+) {
+	var (
+		ad      = newTestAppData()
+		cmdMap  = ScriptCmdMap{}
+		done    = make(chan bool)
+		handled = 0
+	)
+
+	ad.addKeymapCmds(cmdMap)
+	cmdMap["map"]("x xx")
+
+	go func() {
+		ad.HandleKeyMapped("x", func(key string) {
+			handled++
+		})
+		done <- true
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal(`":map x xx" did not stop expanding`)
+	}
+
+	if ad.Fb != "Key mapping recursion limit reached" {
+		t.Errorf("got feedback %q", ad.Fb)
+	}
+	if ad.KeysPending != nil {
+		t.Errorf("keys still pending: %q", ad.KeysPending)
+	}
+	if handled != 0 {
+		t.Errorf("%v keys handled", handled)
+	}
+}
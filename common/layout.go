@@ -0,0 +1,296 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+// Copyright (C) 2024 - 2025  Andy Frank Schoknecht
+
+package common
+
+import (
+	"fmt"
+)
+
+// A Height of 0 makes the row as high as its region needs.
+// Rows with a Flex share the rows left over, weighted by their Flex.
+type layoutRow struct {
+	Region string
+	Height int
+	Flex   int
+}
+
+// The side panel is placed left or right of the content.
+type layoutSide struct {
+	Region   string
+	Position string
+	Width    int
+}
+
+type layoutConfig struct {
+	Rows []layoutRow
+	Side layoutSide
+}
+
+type Rect struct {
+	X int
+	Y int
+	W int
+	H int
+}
+
+// used when the config has no rows
+var defaultLayoutRows = []layoutRow{
+	{Region: "header", Height: 0, Flex: 0},
+	{Region: "title", Height: 0, Flex: 0},
+	{Region: "content", Height: 0, Flex: 1},
+	{Region: "space", Height: 1, Flex: 0},
+	{Region: "lower", Height: 0, Flex: 0},
+}
+
+// DrawLayout draws the common regions where the layout places them,
+// leaving out those the app has nothing for.
+// drawContent is given the area of the content,
+// and contentFit tells how many rows the content needs at a width.
+func (ad *ComAppData) DrawLayout(
+	header      string,
+	title       string,
+	pagerTitle  string,
+	contentFit  func(w int) int,
+	drawContent func(scr *Screen),
+) {
+	var (
+		layout = ad.ComCfg.Layout
+		rects  []Rect
+		side   Rect
+		sub    *Screen
+	)
+
+	layout.Rows = nil
+	for _, v := range ad.ComCfg.Layout.Rows {
+		if ad.fills(v.Region) {
+			layout.Rows = append(layout.Rows, v)
+		}
+	}
+	if ad.fills(layout.Side.Region) == false {
+		layout.Side.Region = ""
+	}
+
+	rects, side = layout.arrange(ad.Scr.W,
+		ad.Scr.H,
+		func(region string, w int) int {
+			switch region {
+			case "content":
+				return contentFit(w)
+
+			case "lower":
+				return lowerRegion(ad.CmdLine, ad.ComCfg).Height(1)
+			}
+
+			return ad.textHeight(region, w, header, title)
+		})
+
+	for i, v := range layout.Rows {
+		sub = ad.Scr.Sub(rects[i].X, rects[i].Y, rects[i].W, rects[i].H)
+
+		switch v.Region {
+		case "content":
+			drawContent(sub)
+
+		case "lower":
+			DrawLower(sub, ad.CmdLine, ad.ComCfg, &ad.Fb, pagerTitle, ad.Ruler)
+
+		case "space":
+
+		default:
+			ad.drawText(sub, v.Region, header, title)
+		}
+	}
+
+	if layout.Side.Region != "" {
+		ad.drawText(ad.Scr.Sub(side.X, side.Y, side.W, side.H),
+			layout.Side.Region,
+			header,
+			title)
+	}
+}
+
+// fills reports whether the app has something to show in the region,
+// the breadcrumb needing a menu path.
+func (ad *ComAppData) fills(
+	region string,
+) bool {
+	if region == "breadcrumb" {
+		return ad.Breadcrumbs != nil
+	}
+
+	return true
+}
+
+// arrange returns the area of each row and of the side panel.
+func (l layoutConfig) arrange(
+	w   int,
+	h   int,
+	fit func(region string, w int) int,
+) ([]Rect, Rect) {
+	var (
+		flexLeft  int
+		flexTotal = 0
+		heights   = make([]int, len(l.Rows))
+		left      = h
+		ret       = make([]Rect, len(l.Rows))
+		side      Rect
+		y         = 0
+		contentW  = l.ContentWidth(w)
+	)
+
+	for i, v := range l.Rows {
+		switch {
+		case v.Flex > 0:
+			flexTotal += v.Flex
+			continue
+
+		case v.Height > 0:
+			heights[i] = v.Height
+
+		case v.Region == "content":
+			heights[i] = fit(v.Region, contentW)
+
+		default:
+			heights[i] = fit(v.Region, w)
+		}
+		left -= heights[i]
+	}
+
+	if left < 0 {
+		left = 0
+	}
+	flexLeft = left
+	for i, v := range l.Rows {
+		if v.Flex > 0 {
+			heights[i] = left * v.Flex / flexTotal
+			flexLeft -= heights[i]
+		}
+	}
+	// rows lost to rounding go to the first flexible row
+	for i, v := range l.Rows {
+		if v.Flex > 0 {
+			heights[i] += flexLeft
+			break
+		}
+	}
+
+	for i, v := range l.Rows {
+		ret[i] = Rect{X: 0, Y: y, W: w, H: heights[i]}
+
+		if v.Region == "content" && l.Side.Region != "" {
+			side = Rect{X: 0, Y: y, W: w - contentW, H: heights[i]}
+			ret[i].W = contentW
+			if l.Side.Position == "left" {
+				ret[i].X = side.W
+			} else {
+				side.X = contentW
+			}
+		}
+
+		y += heights[i]
+	}
+
+	return ret, side
+}
+
+// ContentWidth returns the width of the content on a screen w wide.
+func (l layoutConfig) ContentWidth(
+	w int,
+) int {
+	if l.Side.Region == "" {
+		return w
+	}
+
+	if w - l.Side.Width < 1 {
+		return 1
+	}
+
+	return w - l.Side.Width
+}
+
+func (l *layoutConfig) validate(
+) {
+	var (
+		content = 0
+		lower   = 0
+	)
+
+	if len(l.Rows) == 0 {
+		l.Rows = defaultLayoutRows
+	}
+
+	for _, v := range l.Rows {
+		switch v.Region {
+		case "content":
+			content++
+
+		case "lower":
+			lower++
+
+		case "header":
+		case "title":
+		case "status":
+		case "breadcrumb":
+		case "space":
+
+		default:
+			panic(fmt.Sprintf(`Unknown layout region "%v" in config`,
+				v.Region))
+		}
+
+		if v.Height < 0 || v.Flex < 0 {
+			panic(fmt.Sprintf(`Invalid size of layout region "%v" in config`,
+				v.Region))
+		}
+	}
+
+	if content != 1 || lower != 1 {
+		panic(`Layout in config needs exactly one "content" and one "lower" region`)
+	}
+
+	switch l.Side.Region {
+	case "":
+		return
+
+	case "header":
+	case "title":
+	case "status":
+	case "breadcrumb":
+
+	default:
+		panic(fmt.Sprintf(`Unknown side panel region "%v" in config`,
+			l.Side.Region))
+	}
+
+	switch l.Side.Position {
+	case "left":
+	case "right":
+
+	default:
+		panic(fmt.Sprintf(`Unknown side panel position "%v" in config`,
+			l.Side.Position))
+	}
+
+	if l.Side.Width < 1 {
+		panic(fmt.Sprintf(`Invalid side panel width "%v" in config`,
+			l.Side.Width))
+	}
+}
+
+// uses reports whether the layout shows the given region.
+func (l layoutConfig) uses(
+	region string,
+) bool {
+	if l.Side.Region == region {
+		return true
+	}
+
+	for _, v := range l.Rows {
+		if v.Region == region {
+			return true
+		}
+	}
+
+	return false
+}
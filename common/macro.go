@@ -0,0 +1,202 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+// Copyright (C) 2024 - 2025  Andy Frank Schoknecht
+
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	MacroMaxDepth = 100
+)
+
+const (
+	macroNone = iota
+	macroAwaitRecord
+	macroAwaitPlay
+)
+
+func StateDir(
+) string {
+	var prefix string
+
+	prefix = os.Getenv("XDG_STATE_HOME")
+	if prefix != "" {
+		return filepath.Join(prefix, "hui")
+	}
+
+	prefix = os.Getenv("HOME")
+	if prefix != "" {
+		return filepath.Join(prefix, ".local", "state", "hui")
+	}
+
+	return ""
+}
+
+func (ad *ComAppData) HandleKeyInput(
+	key    string,
+	handle func(key string),
+) {
+	ad.keyBudget = KeyBudget
+	ad.handleKeyInput(key, handle)
+}
+
+func (ad *ComAppData) handleKeyInput(
+	key    string,
+	handle func(key string),
+) {
+	var awaiting = ad.macroAwait
+
+	ad.macroAwait = macroNone
+
+	switch awaiting {
+	case macroAwaitRecord:
+		if validRegister(key) == false {
+			ad.Fb = Feedback(fmt.Sprintf(`Invalid register "%v"`,
+				KeysToNotation([]string{key})))
+			return
+		}
+		ad.MacroRecording = key
+		ad.MacroRecord = nil
+		ad.Fb = Feedback(fmt.Sprintf("Recording @%v", key))
+		return
+
+	case macroAwaitPlay:
+		if ad.MacroRecording != "" && ad.macroDepth == 0 {
+			ad.MacroRecord = append(ad.MacroRecord, ad.ComCfg.Keys.Play, key)
+		}
+		ad.playMacro(key, handle)
+		return
+	}
+
+	if ad.CmdLine.Active == false {
+		switch key {
+		case ad.ComCfg.Keys.Record:
+			if ad.MacroRecording != "" {
+				ad.Macros[ad.MacroRecording] = ad.MacroRecord
+				ad.MacroRecording = ""
+				ad.MacroRecord = nil
+				ad.Fb = ad.saveMacros()
+			} else {
+				ad.macroAwait = macroAwaitRecord
+			}
+			return
+
+		case ad.ComCfg.Keys.Play:
+			ad.macroAwait = macroAwaitPlay
+			return
+		}
+	}
+
+	if ad.MacroRecording != "" && ad.macroDepth == 0 {
+		ad.MacroRecord = append(ad.MacroRecord, key)
+	}
+
+	ad.handleKeyMapped(key, handle, 0)
+}
+
+func (ad *ComAppData) LoadMacros(
+	fileName string,
+) {
+	var (
+		content []byte
+		err     error
+	)
+
+	if StateDir() == "" {
+		return
+	}
+	ad.macroFile = filepath.Join(StateDir(), fileName)
+
+	content, err = os.ReadFile(ad.macroFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	} else if err != nil {
+		ad.Fb = Feedback(fmt.Sprintf("Macro file \"%v\" could not be read:\n%v",
+			ad.macroFile,
+			err))
+		return
+	}
+
+	err = json.Unmarshal(content, &ad.Macros)
+	if err != nil {
+		ad.Fb = Feedback(fmt.Sprintf("Macro file \"%v\" is invalid:\n%v",
+			ad.macroFile,
+			err))
+	}
+}
+
+func (ad *ComAppData) playMacro(
+	register string,
+	handle   func(key string),
+) {
+	var (
+		found bool
+		keys  []string
+	)
+
+	keys, found = ad.Macros[register]
+	if found == false {
+		ad.Fb = Feedback(fmt.Sprintf(`Register "%v" is empty`,
+			KeysToNotation([]string{register})))
+		return
+	}
+
+	if ad.macroDepth >= MacroMaxDepth {
+		ad.Fb = "Macro recursion limit reached"
+		return
+	}
+	ad.macroDepth++
+	defer func() { ad.macroDepth-- }()
+
+	for _, v := range keys {
+		if ad.Active == false {
+			return
+		}
+		if ad.spendKey() == false {
+			ad.Fb = "Macro recursion limit reached"
+			return
+		}
+		ad.handleKeyInput(v, handle)
+	}
+}
+
+func (ad *ComAppData) saveMacros(
+) Feedback {
+	var (
+		content []byte
+		err     error
+	)
+
+	if ad.macroFile == "" {
+		return ""
+	}
+
+	content, err = json.Marshal(ad.Macros)
+	if err != nil {
+		return Feedback(fmt.Sprintf("Macros could not be encoded:\n%v", err))
+	}
+
+	err = os.MkdirAll(filepath.Dir(ad.macroFile), 0755)
+	if err == nil {
+		err = os.WriteFile(ad.macroFile, content, 0644)
+	}
+	if err != nil {
+		return Feedback(fmt.Sprintf("Macro file \"%v\" could not be written:\n%v",
+			ad.macroFile,
+			err))
+	}
+
+	return ""
+}
+
+func validRegister(
+	key string,
+) bool {
+	return len(key) == 1 && key[0] > ' ' && key[0] < 0x7f
+}
@@ -0,0 +1,37 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+// Copyright (C) 2024 - 2025  Andy Frank Schoknecht
+
+package common
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMacroPlayingItselfTwice(
+	t *testing.T,
+) {
+	var (
+		ad   = newTestAppData()
+		done = make(chan bool)
+	)
+
+	ad.ComCfg.Keys.Play = "@"
+	ad.Macros["a"] = []string{"@", "a", "@", "a"}
+
+	go func() {
+		ad.HandleKeyInput("@", func(key string) {})
+		ad.HandleKeyInput("a", func(key string) {})
+		done <- true
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal(`register "a" playing itself twice did not stop`)
+	}
+
+	if ad.Fb != "Macro recursion limit reached" {
+		t.Errorf("got feedback %q", ad.Fb)
+	}
+}
@@ -0,0 +1,125 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+// Copyright (C) 2024 - 2025  Andy Frank Schoknecht
+
+package common
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	SourceMaxDepth = 16
+)
+
+func (ad *ComAppData) SourceRcFile(
+	cmdMap     ScriptCmdMap,
+	rcFileName string,
+	customPath string,
+) {
+	var (
+		f     *os.File
+		fb    Feedback
+		found bool
+	)
+
+	f, found = OpenConfigFile(rcFileName, customPath)
+	if found == false {
+		return
+	}
+	defer f.Close()
+
+	fb = ad.source(cmdMap, f)
+	if fb != "" {
+		ad.Fb = fb
+	}
+}
+
+func (ad *ComAppData) addSourceCmd(
+	cmdMap ScriptCmdMap,
+) {
+	ad.CmdDocs["source"] = "runs each line of the given file as a command"
+	cmdMap["source"] = func(cmd string) Feedback {
+		var (
+			err  error
+			f    *os.File
+			path = strings.TrimSpace(cmd)
+		)
+
+		if path == "" {
+			return "source requires a file"
+		}
+
+		if strings.HasPrefix(path, "~/") {
+			path = filepath.Join(os.Getenv("HOME"), path[2:])
+		}
+
+		f, err = os.Open(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return Feedback(fmt.Sprintf(`File "%v" could not be found`,
+				path))
+		} else if err != nil {
+			return Feedback(fmt.Sprintf("File \"%v\" could not be opened:\n%v",
+				path,
+				err))
+		}
+		defer f.Close()
+
+		return ad.source(cmdMap, f)
+	}
+}
+
+func (ad *ComAppData) source(
+	cmdMap ScriptCmdMap,
+	f      *os.File,
+) Feedback {
+	var (
+		content []byte
+		cursor  int
+		err     error
+		fb      Feedback
+		line    string
+		ret     []string
+	)
+
+	if ad.sourceDepth >= SourceMaxDepth {
+		return Feedback(fmt.Sprintf(`File "%v" exceeds the source depth`,
+			f.Name()))
+	}
+	ad.sourceDepth++
+	defer func() { ad.sourceDepth-- }()
+
+	content, err = io.ReadAll(f)
+	if err != nil {
+		return Feedback(fmt.Sprintf("File \"%v\" could not be read:\n%v",
+			f.Name(),
+			err))
+	}
+
+	for i, v := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(v)
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		line = strings.TrimPrefix(line, ":")
+
+		fb = handleCommand(&ad.Active,
+			CmdLine{Content: line},
+			0,
+			&cursor,
+			cmdMap)
+
+		if fb != "" {
+			ret = append(ret, fmt.Sprintf("%v:%v: %v",
+				f.Name(),
+				i + 1,
+				strings.TrimSpace(string(fb))))
+		}
+	}
+
+	return Feedback(strings.Join(ret, "\n"))
+}
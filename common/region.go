@@ -0,0 +1,364 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+// Copyright (C) 2024 - 2025  Andy Frank Schoknecht
+
+package common
+
+import (
+	"github.com/SchokiCoder/gohui/csi"
+
+	"fmt"
+	"os"
+	"strings"
+)
+
+type Spacing struct {
+	Top    int
+	Right  int
+	Bottom int
+	Left   int
+}
+
+// Region places a block of lines on the screen.
+// Margins stay blank, padding is filled with the background of the style.
+// Without a border, each line is aligned together with its left and right
+// padding, within the columns that are left by the margins,
+// and top and bottom padding rows are as wide as the widest padded line.
+// With a border or a BlockAlignment, the box is as wide as the widest
+// padded line or the border title, the box is aligned within the margins
+// by BlockAlignment, or else Alignment,
+// and each line is aligned within the box by Alignment.
+type Region struct {
+	Alignment      string
+	BlockAlignment string
+	Border         string
+	BorderTitle    string
+	Margin         Spacing
+	Padding        Spacing
+	Style          csi.Style
+}
+
+type borderChars struct {
+	TopLeft     string
+	TopRight    string
+	BottomLeft  string
+	BottomRight string
+	Horizontal  string
+	Vertical    string
+}
+
+var borders = map[string]borderChars{
+	"single":  {"┌", "┐", "└", "┘", "─", "│"},
+	"double":  {"╔", "╗", "╚", "╝", "═", "║"},
+	"rounded": {"╭", "╮", "╰", "╯", "─", "│"},
+	"ascii":   {"+", "+", "+", "+", "-", "|"},
+}
+
+func ValidateBorder(
+	border string,
+) {
+	var found bool
+
+	if border == "" {
+		return
+	}
+
+	_, found = borders[border]
+	if found == false {
+		panic(fmt.Sprintf(`Unknown border "%v" in config`, border))
+	}
+}
+
+func ValidateSpacing(
+	s Spacing,
+) {
+	if s.Top < 0 || s.Right < 0 || s.Bottom < 0 || s.Left < 0 {
+		panic(fmt.Sprintf(`Invalid spacing "%+v" in config`, s))
+	}
+}
+
+// Height returns how many rows the region takes for the given lines.
+func (r Region) Height(
+	lines int,
+) int {
+	return lines + r.VSpace()
+}
+
+// InnerWidth returns how many columns are left for text.
+func (r Region) InnerWidth(
+	areaW int,
+) int {
+	var ret = areaW -
+		r.Margin.Left - r.Margin.Right -
+		r.Padding.Left - r.Padding.Right -
+		2 * r.borderWidth()
+
+	if ret < 1 {
+		ret = 1
+	}
+
+	return ret
+}
+
+// LineX returns the column at which line starts,
+// when it is the only line of the region.
+func (r Region) LineX(
+	areaW int,
+	line  string,
+) int {
+	return r.lineX(areaW, r.boxWidth(areaW, []string{line}), TextWidth(line))
+}
+
+// TopSpace returns how many rows the region takes above its lines.
+func (r Region) TopSpace(
+) int {
+	return r.Margin.Top + r.borderWidth() + r.Padding.Top
+}
+
+func (r Region) VSpace(
+) int {
+	return r.Margin.Top +
+		r.Padding.Top +
+		r.Padding.Bottom +
+		r.Margin.Bottom +
+		2 * r.borderWidth()
+}
+
+// PrintRegion draws lines as region r starting at row y,
+// and returns the number of rows used.
+// If styles is given, it holds the style of each line.
+func (s *Screen) PrintRegion(
+	r      Region,
+	y      int,
+	lines  []string,
+	styles []csi.Style,
+) int {
+	var (
+		boxW  = r.boxWidth(s.W, lines)
+		style csi.Style
+		w     int
+	)
+
+	// without room for both sides of the border, nothing is drawn
+	if r.Border != "" && s.W - r.Margin.Left - r.Margin.Right < 2 {
+		return r.Height(len(lines))
+	}
+
+	y += r.Margin.Top
+
+	if r.Border != "" {
+		s.printBorder(r, y, boxW, true)
+		y++
+	}
+
+	for i := 0; i < r.Padding.Top; i++ {
+		s.printPadding(r,
+			y,
+			boxW,
+			boxW - r.Padding.Left - r.Padding.Right,
+			r.Style)
+		y++
+	}
+
+	for i, v := range lines {
+		style = r.Style
+		if styles != nil {
+			style = styles[i]
+		}
+
+		w = TextWidth(v)
+		s.printPadding(r, y, boxW, w, style)
+		s.Print(r.lineX(s.W, boxW, w), y, style, v)
+		y++
+	}
+
+	for i := 0; i < r.Padding.Bottom; i++ {
+		s.printPadding(r,
+			y,
+			boxW,
+			boxW - r.Padding.Left - r.Padding.Right,
+			r.Style)
+		y++
+	}
+
+	if r.Border != "" {
+		s.printBorder(r, y, boxW, false)
+	}
+
+	return r.Height(len(lines))
+}
+
+func (r Region) borderChars(
+) borderChars {
+	var lang string
+
+	for _, v := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		lang = os.Getenv(v)
+		if lang != "" {
+			break
+		}
+	}
+
+	lang = strings.ToLower(lang)
+	if lang != "" &&
+		strings.Contains(lang, "utf-8") == false &&
+		strings.Contains(lang, "utf8") == false {
+		return borders["ascii"]
+	}
+
+	return borders[r.Border]
+}
+
+func (r Region) borderWidth(
+) int {
+	if r.Border == "" {
+		return 0
+	}
+
+	return 1
+}
+
+// boxWidth returns the width of the widest padded line,
+// or of the border title if that is wider.
+func (r Region) boxWidth(
+	areaW int,
+	lines []string,
+) int {
+	var (
+		ret = 0
+		w   int
+	)
+
+	for _, v := range lines {
+		w = TextWidth(v)
+		if w > ret {
+			ret = w
+		}
+	}
+	ret += r.Padding.Left + r.Padding.Right
+
+	if r.Border != "" && r.BorderTitle != "" {
+		w = TextWidth(r.BorderTitle) + 2
+		if w > ret {
+			ret = w
+		}
+	}
+
+	w = areaW - r.Margin.Left - r.Margin.Right - 2 * r.borderWidth()
+	if ret > w {
+		ret = w
+	}
+	if ret < 0 {
+		ret = 0
+	}
+
+	return ret
+}
+
+func (r Region) blockAlignment(
+) string {
+	if r.BlockAlignment == "" {
+		return r.Alignment
+	}
+
+	return r.BlockAlignment
+}
+
+// boxed reports whether the lines are aligned within a box,
+// rather than each on its own.
+func (r Region) boxed(
+) bool {
+	return r.Border != "" || r.BlockAlignment != ""
+}
+
+func (r Region) boxX(
+	areaW int,
+	boxW  int,
+) int {
+	return r.Margin.Left +
+		csi.AlignX(r.blockAlignment(),
+			boxW + 2 * r.borderWidth(),
+			areaW - r.Margin.Left - r.Margin.Right,
+			0)
+}
+
+func (r Region) lineX(
+	areaW int,
+	boxW  int,
+	lineW int,
+) int {
+	if r.boxed() == false {
+		return r.boxX(areaW, lineW + r.Padding.Left + r.Padding.Right) +
+			r.Padding.Left
+	}
+
+	return r.boxX(areaW, boxW) +
+		r.borderWidth() +
+		r.Padding.Left +
+		csi.AlignX(r.Alignment,
+			lineW,
+			boxW - r.Padding.Left - r.Padding.Right,
+			0)
+}
+
+func (s *Screen) printBorder(
+	r     Region,
+	y     int,
+	boxW  int,
+	top   bool,
+) {
+	var (
+		chars = r.borderChars()
+		line  string
+		title string
+		x     = r.boxX(s.W, boxW)
+	)
+
+	if top {
+		// the title is left out when it does not fit
+		if r.BorderTitle != "" && TextWidth(r.BorderTitle) + 2 <= boxW {
+			title = " " + r.BorderTitle + " "
+		}
+		line = chars.TopLeft +
+			title +
+			strings.Repeat(chars.Horizontal, boxW - TextWidth(title)) +
+			chars.TopRight
+	} else {
+		line = chars.BottomLeft +
+			strings.Repeat(chars.Horizontal, boxW) +
+			chars.BottomRight
+	}
+
+	s.Print(x, y, r.Style, line)
+}
+
+// printPadding fills the background behind a line of width lineW,
+// and draws the vertical border around it.
+func (s *Screen) printPadding(
+	r     Region,
+	y     int,
+	boxW  int,
+	lineW int,
+	style csi.Style,
+) {
+	var (
+		chars = r.borderChars()
+		x     int
+	)
+
+	if r.boxed() == false {
+		boxW = lineW + r.Padding.Left + r.Padding.Right
+		x = r.boxX(s.W, boxW)
+		s.Print(x, y, style, strings.Repeat(" ", boxW))
+		return
+	}
+
+	x = r.boxX(s.W, boxW)
+	if r.Border == "" {
+		s.Print(x, y, style, strings.Repeat(" ", boxW))
+		return
+	}
+
+	s.Print(x, y, r.Style, chars.Vertical)
+	s.Print(x + 1, y, style, strings.Repeat(" ", boxW))
+	s.Print(x + 1 + boxW, y, r.Style, chars.Vertical)
+}
@@ -0,0 +1,65 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+// Copyright (C) 2024 - 2025  Andy Frank Schoknecht
+
+package common
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrintRegionNarrow(
+	t *testing.T,
+) {
+	var (
+		line    string
+		margins = []Spacing{{}, {Left: 2, Right: 2}}
+		r       Region
+		s       *Screen
+	)
+
+	t.Setenv("LANG", "en_US.UTF-8")
+
+	for _, m := range margins {
+		for w := 0; w <= 5; w++ {
+			r = Region{
+				Alignment:   "left",
+				Border:      "single",
+				BorderTitle: "Menu",
+				Margin:      m,
+			}
+			s = NewScreen()
+			s.Resize(w, 5)
+
+			s.PrintRegion(r, 0, []string{"entry"}, nil)
+
+			line = ""
+			for _, v := range s.cells[:w] {
+				line += v.Ch
+			}
+			if strings.Contains(line, "Menu") {
+				t.Errorf("width %v, margin %+v: title drawn without room: %q",
+					w,
+					m,
+					line)
+			}
+		}
+	}
+
+	r = Region{
+		Alignment:   "left",
+		Border:      "single",
+		BorderTitle: "Menu",
+	}
+	s = NewScreen()
+	s.Resize(8, 3)
+	s.PrintRegion(r, 0, []string{"entry"}, nil)
+
+	line = ""
+	for _, v := range s.cells[:8] {
+		line += v.Ch
+	}
+	if line != "┌ Menu ┐" {
+		t.Errorf("title with room: got %q", line)
+	}
+}
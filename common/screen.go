@@ -0,0 +1,346 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+// Copyright (C) 2024 - 2025  Andy Frank Schoknecht
+
+package common
+
+import (
+	"github.com/SchokiCoder/gohui/csi"
+
+	"os"
+	"slices"
+	"strings"
+)
+
+// Ch holds the cell's character,
+// preceded by any escape sequences that came with it.
+// The cell right of a wide character has an empty Ch.
+type Cell struct {
+	Ch    string
+	Style csi.Style
+}
+
+// A Screen made by Sub is a view into its parent,
+// which has no cells of its own.
+type Screen struct {
+	CursorX    int
+	CursorY    int
+	H          int
+	W          int
+	cells      []Cell
+	generation int
+	parent     *Screen
+	prev       []Cell
+	x          int
+	y          int
+}
+
+var blankCell = Cell{Ch: " "}
+
+// Incremented whenever something else drew over the terminal,
+// so that every Screen redraws fully on its next Flush.
+var screenGeneration = 0
+
+func InvalidateScreens(
+) {
+	screenGeneration++
+}
+
+func NewScreen(
+) *Screen {
+	return &Screen{
+		CursorX:    1,
+		CursorY:    1,
+		H:          0,
+		W:          0,
+		cells:      nil,
+		generation: screenGeneration,
+		parent:     nil,
+		prev:       nil,
+		x:          0,
+		y:          0,
+	}
+}
+
+func (s *Screen) Clear(
+) {
+	for i := range s.cells {
+		s.cells[i] = blankCell
+	}
+}
+
+func (s *Screen) Flush(
+) {
+	var (
+		b     strings.Builder
+		first int
+		last  int
+		row   []Cell
+		prow  []Cell
+	)
+
+	if csi.Dumb {
+		s.flushLines()
+		return
+	}
+
+	if s.prev == nil || s.generation != screenGeneration {
+		b.WriteString(csi.Clear)
+		s.prev = make([]Cell, len(s.cells))
+		for i := range s.prev {
+			s.prev[i] = blankCell
+		}
+		s.generation = screenGeneration
+	}
+
+	for y := 0; y < s.H; y++ {
+		row = s.cells[y*s.W : (y+1)*s.W]
+		prow = s.prev[y*s.W : (y+1)*s.W]
+
+		first = -1
+		for x := range row {
+			if row[x] != prow[x] {
+				if first < 0 {
+					first = x
+				}
+				last = x
+			}
+		}
+		if first < 0 {
+			continue
+		}
+		if first > 0 && row[first].Ch == "" {
+			first--
+		}
+
+		// escape sequences may affect any following cell,
+		// so such rows are redrawn as a whole
+		if rowHasEscapes(row) || rowHasEscapes(prow) {
+			first = 0
+			last = s.W - 1
+		}
+
+		b.WriteString(csi.CursorTo(first + 1, y + 1))
+		for x := first; x <= last; x++ {
+			if row[x].Ch == "" {
+				continue
+			}
+			if x == first || row[x].Style != row[x-1].Style {
+				b.WriteString(row[x].Style.String())
+			}
+			b.WriteString(row[x].Ch)
+		}
+		b.WriteString(csi.Reset)
+	}
+
+	copy(s.prev, s.cells)
+
+	b.WriteString(csi.CursorTo(s.CursorX, s.CursorY))
+	os.Stdout.WriteString(b.String())
+}
+
+// flushLines prints the whole screen as plain lines, if anything changed,
+// for terminals that can not move the cursor.
+func (s *Screen) flushLines(
+) {
+	var (
+		b     strings.Builder
+		lines []string
+		row   strings.Builder
+	)
+
+	if s.prev != nil &&
+		s.generation == screenGeneration &&
+		slices.Equal(s.cells, s.prev) {
+		return
+	}
+
+	for y := 0; y < s.H; y++ {
+		row.Reset()
+		for _, v := range s.cells[y*s.W : (y+1)*s.W] {
+			row.WriteString(v.Ch)
+		}
+		lines = append(lines, strings.TrimRight(row.String(), " "))
+	}
+	for len(lines) > 0 && lines[len(lines) - 1] == "" {
+		lines = lines[:len(lines) - 1]
+	}
+
+	for _, v := range lines {
+		b.WriteString(v + "\r\n")
+	}
+	b.WriteString("\r\n")
+
+	s.prev = make([]Cell, len(s.cells))
+	copy(s.prev, s.cells)
+	s.generation = screenGeneration
+
+	os.Stdout.WriteString(b.String())
+}
+
+func (s *Screen) Print(
+	x     int,
+	y     int,
+	style csi.Style,
+	str   string,
+) int {
+	var (
+		last = -1
+		n    = 0
+	)
+
+	if y < 0 || y >= s.H {
+		return 0
+	}
+
+	forEachCell(str, func(ch string, width int) {
+		if width == 0 {
+			if x + n > 0 && x + n <= s.W {
+				s.appendCell(x + n - 1, y, ch)
+			}
+			return
+		}
+
+		if width == 2 && x + n + 1 == s.W {
+			ch = " "
+			width = 1
+		}
+
+		if x + n >= 0 && x + n < s.W {
+			s.setCell(x + n, y, Cell{Ch: ch, Style: style})
+			if width == 2 {
+				s.setCell(x + n + 1, y, Cell{Ch: "", Style: style})
+			}
+			last = x + n
+		}
+		n += width
+	})
+
+	// the escape sequences in str may have changed the style,
+	// so it is set again for whatever follows
+	if last >= 0 && strings.IndexByte(str, '\x1b') >= 0 {
+		s.appendCell(last, y, style.String())
+	}
+
+	return n
+}
+
+func (s *Screen) PrintAligned(
+	alignment string,
+	y         int,
+	style     csi.Style,
+	str       string,
+) int {
+	return s.Print(csi.AlignX(alignment, TextWidth(str), s.W, 0),
+		y,
+		style,
+		str)
+}
+
+func (s *Screen) Resize(
+	w int,
+	h int,
+) {
+	if w == s.W && h == s.H {
+		return
+	}
+
+	s.W = w
+	s.H = h
+	s.cells = make([]Cell, w * h)
+	s.prev = nil
+	s.Clear()
+}
+
+// SetCursor places the terminal cursor at x and y, counted from 1.
+func (s *Screen) SetCursor(
+	x int,
+	y int,
+) {
+	if s.parent != nil {
+		s.parent.SetCursor(x + s.x, y + s.y)
+		return
+	}
+
+	s.CursorX = x
+	s.CursorY = y
+}
+
+// Sub returns a view of the area of s at x and y, sized w and h.
+// Anything printed to the view is clipped to its area.
+func (s *Screen) Sub(
+	x int,
+	y int,
+	w int,
+	h int,
+) *Screen {
+	return &Screen{
+		CursorX:    1,
+		CursorY:    1,
+		H:          h,
+		W:          w,
+		cells:      nil,
+		generation: 0,
+		parent:     s,
+		prev:       nil,
+		x:          x,
+		y:          y,
+	}
+}
+
+func (s *Screen) appendCell(
+	x  int,
+	y  int,
+	ch string,
+) {
+	if s.parent != nil {
+		s.parent.appendCell(x + s.x, y + s.y, ch)
+		return
+	}
+
+	if x < 0 || x >= s.W || y < 0 || y >= s.H {
+		return
+	}
+
+	s.cells[y*s.W + x].Ch += ch
+}
+
+// setCell keeps wide characters whole,
+// by blanking the other half of any wide character that is overwritten.
+func (s *Screen) setCell(
+	x int,
+	y int,
+	c Cell,
+) {
+	var i int
+
+	if s.parent != nil {
+		s.parent.setCell(x + s.x, y + s.y, c)
+		return
+	}
+
+	if x < 0 || x >= s.W || y < 0 || y >= s.H {
+		return
+	}
+	i = y * s.W + x
+
+	if c.Ch != "" && s.cells[i].Ch == "" && x > 0 {
+		s.cells[i-1] = Cell{Ch: " ", Style: s.cells[i-1].Style}
+	}
+	if x + 1 < s.W && s.cells[i+1].Ch == "" && s.cells[i].Ch != "" {
+		s.cells[i+1] = Cell{Ch: " ", Style: s.cells[i+1].Style}
+	}
+
+	s.cells[i] = c
+}
+
+func rowHasEscapes(
+	row []Cell,
+) bool {
+	for _, v := range row {
+		if strings.IndexByte(v.Ch, '\x1b') >= 0 {
+			return true
+		}
+	}
+
+	return false
+}
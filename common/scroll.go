@@ -0,0 +1,300 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+// Copyright (C) 2024 - 2025  Andy Frank Schoknecht
+
+package common
+
+import (
+	"github.com/SchokiCoder/gohui/csi"
+
+	"fmt"
+	"strings"
+)
+
+// In the markers, "{n}" is replaced by the count of items above or below.
+// In Position, "{line}", "{lines}" and "{percent}" are replaced.
+// An empty marker or position is not shown.
+// ScrollOff is how many items around the cursor are kept in view,
+// Center keeps the cursor in the middle of the view instead.
+type scrollConfig struct {
+	ScrollOff   int
+	Center      bool
+	MarkerAbove string
+	MarkerBelow string
+	Scrollbar   bool
+	BarTrack    string
+	BarThumb    string
+	Position    string
+	Fg          csi.FgColor
+	Bg          csi.BgColor
+	Attrs       csi.Attrs
+}
+
+func (c scrollConfig) validate(
+) {
+	if c.ScrollOff < 0 {
+		panic(fmt.Sprintf(`Invalid scroll off "%v" in config`, c.ScrollOff))
+	}
+
+	if c.Scrollbar && (TextWidth(c.BarTrack) != 1 || TextWidth(c.BarThumb) != 1) {
+		panic(fmt.Sprintf(`Scrollbar chars "%v" and "%v" in config must be one cell wide`,
+			c.BarTrack,
+			c.BarThumb))
+	}
+}
+
+// BarWidth returns how many columns the scrollbar takes.
+func (c scrollConfig) BarWidth(
+) int {
+	if c.Scrollbar {
+		return 1
+	}
+
+	return 0
+}
+
+// Fit returns how many items from begin are shown in height rows,
+// and whether the markers above and below are shown,
+// given how many rows each item takes.
+// The last item shown may be cut.
+// A marker is left out when it would leave no row for the items.
+func (c scrollConfig) Fit(
+	rows   []int,
+	begin  int,
+	height int,
+) (int, bool, bool) {
+	var (
+		above = begin > 0 && c.MarkerAbove != "" && height > 1
+		below bool
+		n     int
+	)
+
+	if above {
+		height--
+	}
+
+	n = fitItems(rows, begin, height)
+	if begin + n < len(rows) && c.MarkerBelow != "" && height > 1 {
+		below = true
+		n = fitItems(rows, begin, height - 1)
+	}
+
+	return n, above, below
+}
+
+// Clamp lowers offset, as long as that still shows every item to the end,
+// so that no rows are left empty at the bottom.
+func (c scrollConfig) Clamp(
+	offset *int,
+	rows   []int,
+	height int,
+) {
+	if *offset >= len(rows) {
+		*offset = len(rows) - 1
+	}
+	for *offset > 0 && *offset - 1 + c.visible(rows, *offset - 1, height) >= len(rows) {
+		*offset--
+	}
+	if *offset < 0 {
+		*offset = 0
+	}
+}
+
+// Follow moves offset so that the cursor is shown,
+// with the configured context around it.
+func (c scrollConfig) Follow(
+	offset *int,
+	cursor int,
+	rows   []int,
+	height int,
+) {
+	var (
+		context = c.ScrollOff
+		first   int
+		last    int
+		used    = 0
+	)
+
+	if len(rows) == 0 {
+		*offset = 0
+		return
+	}
+
+	if c.Center {
+		*offset = cursor
+		for *offset > 0 && used + rows[*offset - 1] <= (height - rows[cursor]) / 2 {
+			*offset--
+			used += rows[*offset]
+		}
+		c.Clamp(offset, rows, height)
+		return
+	}
+
+	if context > (height - 1) / 2 {
+		context = (height - 1) / 2
+	}
+	first = cursor - context
+	if first < 0 {
+		first = 0
+	}
+	last = cursor + context
+	if last > len(rows) - 1 {
+		last = len(rows) - 1
+	}
+
+	if *offset > first {
+		*offset = first
+	}
+	for *offset < cursor && *offset + c.visible(rows, *offset, height) <= last {
+		*offset++
+	}
+	c.Clamp(offset, rows, height)
+}
+
+func (c scrollConfig) Marker(
+	format string,
+	n      int,
+) string {
+	return strings.ReplaceAll(format, "{n}", fmt.Sprint(n))
+}
+
+func (c scrollConfig) Style(
+) csi.Style {
+	return csi.Style{Fg: c.Fg, Bg: c.Bg, Attrs: c.Attrs}
+}
+
+// DrawScrollbar draws the scrollbar into the last column of scr,
+// for height rows from y, if not all of the total items are shown.
+func DrawScrollbar(
+	scr    *Screen,
+	comCfg ComConfig,
+	y      int,
+	height int,
+	offset int,
+	shown  int,
+	total  int,
+) {
+	var (
+		ch     string
+		thumbH int
+		thumbY int
+	)
+
+	if comCfg.Scroll.Scrollbar == false || total <= shown || height < 1 {
+		return
+	}
+
+	thumbH = height * shown / total
+	if thumbH < 1 {
+		thumbH = 1
+	}
+	thumbY = offset * (height - thumbH) / (total - shown)
+	if offset > 0 && thumbY == 0 && height > thumbH {
+		thumbY = 1
+	}
+	if thumbY > height - thumbH {
+		thumbY = height - thumbH
+	}
+
+	for i := 0; i < height; i++ {
+		ch = comCfg.Scroll.BarTrack
+		if i >= thumbY && i < thumbY + thumbH {
+			ch = comCfg.Scroll.BarThumb
+		}
+		scr.Print(scr.W - 1, y + i, comCfg.Scroll.Style(), ch)
+	}
+}
+
+// PositionText returns the position indicator
+// for height rows shown from offset of total rows.
+func (c scrollConfig) PositionText(
+	offset int,
+	height int,
+	total  int,
+) string {
+	var (
+		end     = offset + height
+		percent = 100
+	)
+
+	if c.Position == "" {
+		return ""
+	}
+
+	if end > total {
+		end = total
+	}
+	if total > 0 {
+		percent = end * 100 / total
+	}
+
+	return strings.NewReplacer(
+		"{line}", fmt.Sprint(offset + 1),
+		"{lines}", fmt.Sprint(total),
+		"{percent}", fmt.Sprint(percent),
+	).Replace(c.Position)
+}
+
+// Page returns how many items from begin are shown whole in height rows,
+// which is how far a page moves.
+func (c scrollConfig) Page(
+	rows   []int,
+	begin  int,
+	height int,
+) int {
+	return c.visible(rows, begin, height)
+}
+
+// visible returns how many items from begin are shown whole,
+// at least the first one.
+func (c scrollConfig) visible(
+	rows   []int,
+	begin  int,
+	height int,
+) int {
+	var (
+		above, below bool
+		full         = 0
+		n            int
+		used         = 0
+	)
+
+	n, above, below = c.Fit(rows, begin, height)
+	if above {
+		height--
+	}
+	if below {
+		height--
+	}
+
+	for i := begin; i < begin + n; i++ {
+		used += rows[i]
+		if used <= height {
+			full++
+		}
+	}
+
+	if full == 0 && n > 0 {
+		full = 1
+	}
+
+	return full
+}
+
+// fitItems returns how many items from begin start within height rows.
+func fitItems(
+	rows   []int,
+	begin  int,
+	height int,
+) int {
+	var (
+		n    = 0
+		used = 0
+	)
+
+	for i := begin; i < len(rows) && used < height; i++ {
+		used += rows[i]
+		n++
+	}
+
+	return n
+}
@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+// Copyright (C) 2024 - 2025  Andy Frank Schoknecht
+
+package common
+
+import (
+	"testing"
+)
+
+func TestFitShowsCursor(
+	t *testing.T,
+) {
+	var (
+		above, below bool
+		configs      []scrollConfig
+		n            int
+		offset       int
+		rowSets      = [][]int{
+			{1, 1, 1, 1, 1, 1, 1, 1},
+			{1, 2, 1, 3, 1, 2, 2, 1},
+		}
+		used         int
+	)
+
+	for _, off := range []int{0, 1, 3} {
+		for _, center := range []bool{false, true} {
+			configs = append(configs, scrollConfig{
+				ScrollOff:   off,
+				Center:      center,
+				MarkerAbove: "▲ {n} more",
+				MarkerBelow: "▼ {n} more",
+			})
+		}
+	}
+
+	for _, c := range configs {
+		for _, rows := range rowSets {
+			for height := 1; height <= 2; height++ {
+				for start := range rows {
+					for cursor := range rows {
+						offset = start
+						c.Follow(&offset, cursor, rows, height)
+						n, above, below = c.Fit(rows, offset, height)
+
+						used = n
+						if above {
+							used++
+						}
+						if below {
+							used++
+						}
+
+						if cursor < offset || cursor >= offset + n || used > height {
+							t.Errorf("%+v, rows %v, height %v, from %v to %v: offset %v, n %v, above %v, below %v",
+								c,
+								rows,
+								height,
+								start,
+								cursor,
+								offset,
+								n,
+								above,
+								below)
+						}
+					}
+				}
+			}
+		}
+	}
+}
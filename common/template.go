@@ -0,0 +1,191 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+// Copyright (C) 2024 - 2025  Andy Frank Schoknecht
+
+package common
+
+import (
+	"os"
+	"os/user"
+	"strings"
+	"time"
+)
+
+// TimeFormat and DateFormat are Go time layouts.
+// Refresh is how many seconds pass between redraws, to keep the time
+// current, 0 redraws only on input.
+// CmdInterval is how many seconds the output of a command is kept,
+// 0 runs each command only once.
+type templateConfig struct {
+	TimeFormat  string
+	DateFormat  string
+	Refresh     int
+	CmdInterval int
+}
+
+type templateCmd struct {
+	Output  string
+	Running bool
+	Time    time.Time
+}
+
+// RefreshInterval returns the time between redraws, 0 for none.
+func (c templateConfig) RefreshInterval(
+) time.Duration {
+	return time.Duration(c.Refresh) * time.Second
+}
+
+// expandTemplate replaces the variables in str:
+// "{user}", "{host}", "{time}", "{date}", "{menu_path}",
+// "{env:VAR}" by the environment variable
+// and "{cmd:COMMAND}" by the output of the shell command.
+// Anything else in braces is kept, for the markup.
+func (ad *ComAppData) expandTemplate(
+	str string,
+) string {
+	var (
+		end   int
+		found bool
+		ret   strings.Builder
+		val   string
+	)
+
+	for i := 0; i < len(str); i++ {
+		if strings.HasPrefix(str[i:], "{{") {
+			ret.WriteString("{{")
+			i++
+			continue
+		}
+
+		if str[i] == '{' {
+			end = strings.IndexByte(str[i:], '}')
+			if end > 0 {
+				val, found = ad.templateVar(str[i+1 : i+end])
+				if found {
+					ret.WriteString(val)
+					i += end
+					continue
+				}
+			}
+		}
+
+		ret.WriteByte(str[i])
+	}
+
+	return ret.String()
+}
+
+func (ad *ComAppData) templateVar(
+	name string,
+) (string, bool) {
+	var (
+		err    error
+		format string
+		ret    string
+		u      *user.User
+	)
+
+	switch {
+	case strings.HasPrefix(name, "env:"):
+		return os.Getenv(name[len("env:"):]), true
+
+	case strings.HasPrefix(name, "cmd:"):
+		return ad.templateCmdOutput(name[len("cmd:"):]), true
+	}
+
+	switch name {
+	case "user":
+		u, err = user.Current()
+		if err != nil {
+			return os.Getenv("USER"), true
+		}
+		return u.Username, true
+
+	case "host":
+		ret, err = os.Hostname()
+		if err != nil {
+			return "", true
+		}
+		return ret, true
+
+	case "time":
+		format = ad.ComCfg.Template.TimeFormat
+		if format == "" {
+			format = "15:04"
+		}
+		return time.Now().Format(format), true
+
+	case "date":
+		format = ad.ComCfg.Template.DateFormat
+		if format == "" {
+			format = "2006-01-02"
+		}
+		return time.Now().Format(format), true
+
+	case "menu_path":
+		return ad.MenuPath, true
+	}
+
+	return "", false
+}
+
+// templateCmdOutput returns the last output of the command,
+// and runs it in the background when that output is due.
+func (ad *ComAppData) templateCmdOutput(
+	cmd string,
+) string {
+	var (
+		c        *templateCmd
+		found    bool
+		interval = time.Duration(ad.ComCfg.Template.CmdInterval) * time.Second
+	)
+
+	c, found = ad.templateCmds[cmd]
+	if found == false {
+		c = &templateCmd{}
+		ad.templateCmds[cmd] = c
+	}
+
+	if c.Running ||
+		(c.Time.IsZero() == false &&
+			(interval <= 0 || time.Since(c.Time) < interval)) {
+		return c.Output
+	}
+
+	c.Running = true
+	if ad.Events == nil {
+		c.Output = runTemplateCmd(cmd)
+		c.Time = time.Now()
+		c.Running = false
+		return c.Output
+	}
+
+	ad.Events.Job(func() func() {
+		var output = runTemplateCmd(cmd)
+
+		return func() {
+			c.Output = output
+			c.Time = time.Now()
+			c.Running = false
+		}
+	})
+
+	return c.Output
+}
+
+// runTemplateCmd returns the output of the command,
+// without the trailing newline.
+func runTemplateCmd(
+	cmd string,
+) string {
+	var (
+		err error
+		out []byte
+	)
+
+	out, err = shellCommand(cmd, nil).Output()
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimRight(string(out), "\n")
+}
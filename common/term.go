@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+// Copyright (C) 2024 - 2025  Andy Frank Schoknecht
+
+package common
+
+import (
+	"github.com/SchokiCoder/gohui/csi"
+
+	"fmt"
+	"golang.org/x/term"
+	"os"
+	"os/signal"
+	"runtime/debug"
+	"syscall"
+)
+
+var (
+	termActive bool
+	termState  *term.State
+)
+
+func RecoverTerm(
+) {
+	var r = recover()
+
+	if r == nil {
+		return
+	}
+
+	RestoreTerm()
+	fmt.Fprintf(os.Stderr, "panic: %v\n\n%s", r, debug.Stack())
+	os.Exit(2)
+}
+
+func RestoreTerm(
+) {
+	suspendTerm()
+	termActive = false
+}
+
+func SetupTerm(
+) {
+	var (
+		err  error
+		sigs = make(chan os.Signal, 1)
+	)
+
+	termState, err = term.GetState(int(os.Stdin.Fd()))
+	if err != nil {
+		panic(fmt.Sprintf("Could not get terminal state:\n%v", err))
+	}
+
+	termActive = true
+	resumeTerm()
+
+	signal.Notify(sigs, syscall.SIGTERM, syscall.SIGHUP)
+	go func() {
+		var sig = <-sigs
+
+		RestoreTerm()
+		os.Exit(128 + int(sig.(syscall.Signal)))
+	}()
+}
+
+func resumeTerm(
+) {
+	var err error
+
+	if termActive == false {
+		return
+	}
+
+	_, err = term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		panic(fmt.Sprintf("Switching to raw mode failed:\n%v", err))
+	}
+
+	csi.Send(csi.AltScreenEnter)
+	csi.Send(csi.CursorHide)
+	InvalidateScreens()
+}
+
+func suspendTerm(
+) {
+	if termActive == false {
+		return
+	}
+
+	csi.Send(csi.Reset)
+	csi.Send(csi.CursorShow)
+	csi.Send(csi.AltScreenLeave)
+	term.Restore(int(os.Stdin.Fd()), termState)
+}
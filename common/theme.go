@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+// Copyright (C) 2024 - 2025  Andy Frank Schoknecht
+
+package common
+
+import (
+	"github.com/SchokiCoder/gohui/csi"
+
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// A theme file sets the color of each role,
+// like {"Palette": {"accent": "#87afd7", "muted": "grey"}}.
+// It is found in the "themes" directory next to the config files.
+type themeConfig struct {
+	Palette map[string]csi.Color
+}
+
+func (ad *ComAppData) addThemeCmd(
+	cmdMap ScriptCmdMap,
+) {
+	ad.CmdDocs["theme"] = "switches to the given theme, shows the current one without args"
+	cmdMap["theme"] = func(cmd string) Feedback {
+		var (
+			fb   Feedback
+			name = strings.TrimSpace(cmd)
+		)
+
+		if name == "" {
+			if ad.ComCfg.Theme == "" {
+				return "No theme"
+			}
+			return Feedback(ad.ComCfg.Theme)
+		}
+
+		fb = applyTheme(name, ad.cfgPath)
+		if fb == "" {
+			ad.ComCfg.Theme = name
+		}
+
+		return fb
+	}
+}
+
+// applyTheme makes the colors of the named theme the palette.
+func applyTheme(
+	name       string,
+	customPath string,
+) Feedback {
+	var (
+		content []byte
+		err     error
+		f       *os.File
+		found   bool
+		theme   themeConfig
+	)
+
+	if strings.ContainsAny(name, `/\`) {
+		return Feedback(fmt.Sprintf(`Invalid theme name "%v"`, name))
+	}
+
+	f, found = OpenConfigFile(filepath.Join("themes", name + ".json"),
+		customPath)
+	if found == false {
+		return Feedback(fmt.Sprintf(`Theme "%v" could not be found`, name))
+	}
+	defer f.Close()
+
+	content, err = io.ReadAll(f)
+	if err != nil {
+		return Feedback(fmt.Sprintf("Theme file \"%v\" could not be read:\n%v",
+			f.Name(),
+			err))
+	}
+
+	err = json.Unmarshal(content, &theme)
+	if err != nil {
+		return Feedback(fmt.Sprintf("Theme file \"%v\" is invalid:\n%v",
+			f.Name(),
+			err))
+	}
+
+	for k, v := range theme.Palette {
+		if csi.IsRole(k) == false {
+			return Feedback(fmt.Sprintf(`Unknown role "%v" in theme "%v"`,
+				k,
+				name))
+		}
+		if v.Role != "" {
+			return Feedback(fmt.Sprintf(`Role "%v" in theme "%v" is set to another role`,
+				k,
+				name))
+		}
+	}
+
+	csi.Palette = theme.Palette
+	InvalidateScreens()
+
+	return ""
+}
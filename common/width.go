@@ -0,0 +1,239 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+// Copyright (C) 2024 - 2025  Andy Frank Schoknecht
+
+package common
+
+import (
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	TabWidth = 8
+)
+
+const (
+	zeroWidthJoiner     = 0x200d
+	variationSelectorEm = 0xfe0f
+)
+
+// East Asian Wide and Fullwidth ranges, plus emoji presented as wide
+var wideRunes = [][2]rune{
+	{0x1100, 0x115f}, {0x231a, 0x231b}, {0x2329, 0x232a},
+	{0x23e9, 0x23ec}, {0x23f0, 0x23f0}, {0x23f3, 0x23f3},
+	{0x25fd, 0x25fe}, {0x2614, 0x2615}, {0x2648, 0x2653},
+	{0x267f, 0x267f}, {0x2693, 0x2693}, {0x26a1, 0x26a1},
+	{0x26aa, 0x26ab}, {0x26bd, 0x26be}, {0x26c4, 0x26c5},
+	{0x26ce, 0x26ce}, {0x26d4, 0x26d4}, {0x26ea, 0x26ea},
+	{0x26f2, 0x26f3}, {0x26f5, 0x26f5}, {0x26fa, 0x26fa},
+	{0x26fd, 0x26fd}, {0x2705, 0x2705}, {0x270a, 0x270b},
+	{0x2728, 0x2728}, {0x274c, 0x274c}, {0x274e, 0x274e},
+	{0x2753, 0x2755}, {0x2757, 0x2757}, {0x2795, 0x2797},
+	{0x27b0, 0x27b0}, {0x27bf, 0x27bf}, {0x2b1b, 0x2b1c},
+	{0x2b50, 0x2b50}, {0x2b55, 0x2b55}, {0x2e80, 0x303e},
+	{0x3041, 0x33ff}, {0x3400, 0x4dbf}, {0x4e00, 0x9fff},
+	{0xa000, 0xa4cf}, {0xa960, 0xa97f}, {0xac00, 0xd7a3},
+	{0xf900, 0xfaff}, {0xfe10, 0xfe19}, {0xfe30, 0xfe6f},
+	{0xff00, 0xff60}, {0xffe0, 0xffe6}, {0x16fe0, 0x16fe4},
+	{0x17000, 0x18aff}, {0x1b000, 0x1b2ff}, {0x1f004, 0x1f004},
+	{0x1f0cf, 0x1f0cf}, {0x1f18e, 0x1f18e}, {0x1f191, 0x1f19a},
+	{0x1f200, 0x1f202}, {0x1f210, 0x1f23b}, {0x1f240, 0x1f248},
+	{0x1f250, 0x1f251}, {0x1f260, 0x1f265}, {0x1f300, 0x1f320},
+	{0x1f32d, 0x1f335}, {0x1f337, 0x1f37c}, {0x1f37e, 0x1f393},
+	{0x1f3a0, 0x1f3ca}, {0x1f3cf, 0x1f3d3}, {0x1f3e0, 0x1f3f0},
+	{0x1f3f4, 0x1f3f4}, {0x1f3f8, 0x1f43e}, {0x1f440, 0x1f440},
+	{0x1f442, 0x1f4fc}, {0x1f4ff, 0x1f53d}, {0x1f54b, 0x1f54e},
+	{0x1f550, 0x1f567}, {0x1f57a, 0x1f57a}, {0x1f595, 0x1f596},
+	{0x1f5a4, 0x1f5a4}, {0x1f5fb, 0x1f64f}, {0x1f680, 0x1f6c5},
+	{0x1f6cc, 0x1f6cc}, {0x1f6d0, 0x1f6d2}, {0x1f6d5, 0x1f6d7},
+	{0x1f6dc, 0x1f6df}, {0x1f6eb, 0x1f6ec}, {0x1f6f4, 0x1f6fc},
+	{0x1f7e0, 0x1f7eb}, {0x1f7f0, 0x1f7f0}, {0x1f90c, 0x1f93a},
+	{0x1f93c, 0x1f945}, {0x1f947, 0x1f9ff}, {0x1fa70, 0x1faff},
+	{0x20000, 0x2fffd}, {0x30000, 0x3fffd},
+}
+
+func RuneWidth(
+	r rune,
+) int {
+	var i int
+
+	switch {
+	case r < ' ' || r == 0x7f:
+		return 0
+
+	case r < 0x300:
+		return 1
+
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+
+	case r >= 0x1160 && r <= 0x11ff:
+		return 0
+
+	case r >= 0x1f3fb && r <= 0x1f3ff:
+		// skin tone modifiers
+		return 0
+	}
+
+	i = sort.Search(len(wideRunes), func(i int) bool {
+		return wideRunes[i][1] >= r
+	})
+	if i < len(wideRunes) && wideRunes[i][0] <= r {
+		return 2
+	}
+
+	return 1
+}
+
+// TextWidth returns how many terminal cells str takes,
+// ignoring escape sequences and expanding tabs.
+func TextWidth(
+	str string,
+) int {
+	var n = 0
+
+	forEachCell(str, func(ch string, width int) {
+		n += width
+	})
+
+	return n
+}
+
+// clusterAt measures the cluster at the start of str,
+// which is an escape sequence, a control character or a grapheme.
+// Tabs are measured from col.
+func clusterAt(
+	str string,
+	col int,
+) (n int, width int, esc bool) {
+	var (
+		r    rune
+		size int
+	)
+
+	switch {
+	case str[0] == '\x1b':
+		return escapeLen(str), 0, true
+
+	case str[0] == '\t':
+		return 1, TabWidth - col % TabWidth, false
+
+	case str[0] < ' ' || str[0] == 0x7f:
+		return 1, 0, false
+	}
+
+	r, n = utf8.DecodeRuneInString(str)
+	width = RuneWidth(r)
+
+	if r >= 0x1f1e6 && r <= 0x1f1ff {
+		// regional indicators pair up into flags
+		r, size = utf8.DecodeRuneInString(str[n:])
+		if r >= 0x1f1e6 && r <= 0x1f1ff {
+			return n + size, 2, false
+		}
+	}
+
+	for n < len(str) {
+		r, size = utf8.DecodeRuneInString(str[n:])
+
+		if r == zeroWidthJoiner {
+			n += size
+			if n < len(str) {
+				_, size = utf8.DecodeRuneInString(str[n:])
+				n += size
+			}
+			continue
+		}
+
+		if r < 0x300 || RuneWidth(r) != 0 {
+			break
+		}
+
+		if r == variationSelectorEm && width == 1 {
+			width = 2
+		}
+		n += size
+	}
+
+	return n, width, false
+}
+
+func escapeLen(
+	str string,
+) int {
+	if len(str) < 2 {
+		return len(str)
+	}
+
+	switch str[1] {
+	case '[':
+		for i := 2; i < len(str); i++ {
+			if str[i] >= 0x40 && str[i] <= 0x7e {
+				return i + 1
+			}
+		}
+
+	case ']':
+		for i := 2; i < len(str); i++ {
+			if str[i] == '\a' {
+				return i + 1
+			}
+			if str[i] == '\x1b' && i + 1 < len(str) && str[i+1] == '\\' {
+				return i + 2
+			}
+		}
+
+	default:
+		return 2
+	}
+
+	return len(str)
+}
+
+// forEachCell calls fn for every visible grapheme of str with its width.
+// Escape sequences and zero-width graphemes are prefixed to the next one,
+// those at the very end are given with a width of 0.
+func forEachCell(
+	str string,
+	fn  func(ch string, width int),
+) {
+	var (
+		col    = 0
+		esc    bool
+		n      int
+		prefix string
+		width  int
+	)
+
+	for i := 0; i < len(str); i += n {
+		n, width, esc = clusterAt(str[i:], col)
+
+		switch {
+		case esc:
+			prefix += str[i:i+n]
+
+		case str[i] == '\t':
+			fn(prefix + " ", 1)
+			prefix = ""
+			for j := 1; j < width; j++ {
+				fn(" ", 1)
+			}
+
+		case width == 0:
+			if str[i] >= ' ' && str[i] != 0x7f {
+				prefix += str[i:i+n]
+			}
+
+		default:
+			fn(prefix + str[i:i+n], width)
+			prefix = ""
+		}
+
+		col += width
+	}
+
+	if prefix != "" {
+		fn(prefix, 0)
+	}
+}
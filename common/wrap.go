@@ -0,0 +1,230 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+// Copyright (C) 2024 - 2025  Andy Frank Schoknecht
+
+package common
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	Ellipsis = "…"
+)
+
+// Wrap modes, an empty mode is WrapHard.
+const (
+	WrapHard = "hard"
+	WrapWord = "word"
+	WrapNone = "none"
+)
+
+func ValidateWrap(
+	wrap   string,
+	indent int,
+) {
+	switch wrap {
+	case "":
+	case WrapHard:
+	case WrapWord:
+	case WrapNone:
+
+	default:
+		panic(fmt.Sprintf(`Unknown wrap mode "%v" in config`, wrap))
+	}
+
+	if indent < 0 {
+		panic(fmt.Sprintf(`Invalid wrap indent "%v" in config`, indent))
+	}
+}
+
+// WrapText splits str into lines of at most maxLineLen cells.
+// Lines continued by wrapping are indented by indent spaces,
+// unless the indent would leave no room for text.
+// In WrapNone mode, lines too long are cut and end with an ellipsis.
+func WrapText(
+	maxLineLen int,
+	str        string,
+	wrap       string,
+	indent     int,
+) []string {
+	var ret []string
+
+	if indent >= maxLineLen {
+		indent = 0
+	}
+
+	for _, v := range strings.Split(str, "\n") {
+		switch wrap {
+		case WrapNone:
+			ret = append(ret, Truncate(maxLineLen, v, false))
+
+		case WrapWord:
+			ret = append(ret, wrapLine(maxLineLen, v, indent, true)...)
+
+		default:
+			ret = append(ret, wrapLine(maxLineLen, v, indent, false)...)
+		}
+	}
+
+	return ret
+}
+
+// Truncate cuts str to maxLen cells, ending it with an ellipsis if cut.
+// With more set, the ellipsis is added anyway.
+func Truncate(
+	maxLen int,
+	str    string,
+	more   bool,
+) string {
+	var (
+		esc     bool
+		line    strings.Builder
+		lineLen = 0
+		n       int
+		width   int
+	)
+
+	if more == false && TextWidth(str) <= maxLen {
+		return str
+	}
+	if maxLen <= 0 {
+		return ""
+	}
+
+	for i := 0; i < len(str); i += n {
+		n, width, esc = clusterAt(str[i:], lineLen)
+
+		if esc == false && lineLen + width > maxLen - 1 {
+			break
+		}
+
+		line.WriteString(str[i:i+n])
+		lineLen += width
+	}
+	line.WriteString(Ellipsis)
+
+	return line.String()
+}
+
+// TruncateLeft cuts str from the left to maxLen cells,
+// starting it with an ellipsis if cut.
+func TruncateLeft(
+	maxLen int,
+	str    string,
+) string {
+	var n int
+
+	if TextWidth(str) <= maxLen {
+		return str
+	}
+	if maxLen <= 0 {
+		return ""
+	}
+
+	for i := 0; i < len(str); i += n {
+		if TextWidth(str[i:]) <= maxLen - 1 {
+			return Ellipsis + str[i:]
+		}
+		n, _, _ = clusterAt(str[i:], 0)
+	}
+
+	return Ellipsis
+}
+
+func wrapLine(
+	maxLineLen int,
+	str        string,
+	indent     int,
+	words      bool,
+) []string {
+	var (
+		brk     = -1
+		esc     bool
+		lineLen = 0
+		limit   = maxLineLen
+		n       int
+		ret     []string
+		start   = 0
+		width   int
+	)
+
+	if TextWidth(str) <= maxLineLen {
+		return []string{str}
+	}
+
+	for i := 0; i < len(str); i += n {
+		n, width, esc = clusterAt(str[i:], lineLen)
+
+		if esc == false && lineLen + width > limit && lineLen > 0 {
+			if words && brk > start {
+				ret = append(ret, strings.TrimRight(str[start:brk], " "))
+				start = brk
+				for start < len(str) && str[start] == ' ' {
+					start++
+				}
+			} else {
+				ret = append(ret, str[start:i])
+				start = i
+			}
+
+			brk = -1
+			lineLen = 0
+			limit = maxLineLen - indent
+			n = 0
+			i = start
+			continue
+		}
+
+		if str[i] == ' ' {
+			brk = i
+		}
+		lineLen += width
+	}
+
+	if start < len(str) {
+		ret = append(ret, str[start:])
+	}
+
+	for i := 1; i < len(ret); i++ {
+		ret[i] = strings.Repeat(" ", indent) +
+			activeEscapes(strings.Join(ret[:i], "")) +
+			ret[i]
+	}
+
+	return ret
+}
+
+// activeEscapes returns the escape sequences setting the style,
+// that are still in effect at the end of str.
+func activeEscapes(
+	str string,
+) string {
+	var (
+		end int
+		ret string
+		seq string
+	)
+
+	for i := strings.Index(str, "\x1b["); i >= 0; i = strings.Index(str, "\x1b[") {
+		str = str[i:]
+		end = strings.IndexFunc(str[2:], func(r rune) bool {
+			return (r < '0' || r > '9') && r != ';'
+		})
+		if end < 0 {
+			break
+		}
+		seq = str[:2+end+1]
+		str = str[2+end+1:]
+
+		if seq[len(seq) - 1] != 'm' {
+			continue
+		}
+		if seq == "\x1b[m" || seq == "\x1b[0m" || strings.HasPrefix(seq, "\x1b[0;") {
+			ret = ""
+		}
+		ret += seq
+	}
+
+	return ret
+}
@@ -4,33 +4,386 @@
 package csi
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
 )
 
-type FgColor struct {
-	Active bool
+const (
+	ColorsNone = 0
+	Colors16   = 16
+	Colors256  = 256
+	ColorsTrue = 1 << 24
+)
+
+// How many colors the terminal can show, colors are downsampled to fit.
+// With ColorsNone, no color sequences are sent at all.
+var ColorDepth = DetectColorDepth()
+
+// Roles name the colors of the palette.
+var Roles = []string{"accent", "muted", "error", "hover"}
+
+// Palette holds the color of each role, as set by a theme.
+// A role without a color shows the default color.
+var Palette = map[string]Color{}
+
+// A color is either given by R, G and B,
+// or by Index into the terminal's palette of 256 colors,
+// or by the Role whose color in the Palette it takes.
+// In configs it can also be written as "#rrggbb", a color name,
+// a role, "default" or a palette index.
+type Color struct {
+	Active  bool
 	R, G, B uint
+	Indexed bool
+	Index   uint
+	Role    string
+}
+
+type FgColor Color
+
+type BgColor Color
+
+var colorNames = []string{
+	"black",
+	"red",
+	"green",
+	"yellow",
+	"blue",
+	"magenta",
+	"cyan",
+	"white",
+}
+
+// the usual xterm colors
+var palette16 = [16][3]uint{
+	{0x00, 0x00, 0x00}, {0xcd, 0x00, 0x00},
+	{0x00, 0xcd, 0x00}, {0xcd, 0xcd, 0x00},
+	{0x00, 0x00, 0xee}, {0xcd, 0x00, 0xcd},
+	{0x00, 0xcd, 0xcd}, {0xe5, 0xe5, 0xe5},
+	{0x7f, 0x7f, 0x7f}, {0xff, 0x00, 0x00},
+	{0x00, 0xff, 0x00}, {0xff, 0xff, 0x00},
+	{0x5c, 0x5c, 0xff}, {0xff, 0x00, 0xff},
+	{0x00, 0xff, 0xff}, {0xff, 0xff, 0xff},
+}
+
+var cubeLevels = [6]uint{0, 95, 135, 175, 215, 255}
+
+func DetectColorDepth(
+) int {
+	var (
+		colorterm = os.Getenv("COLORTERM")
+		term      = os.Getenv("TERM")
+	)
+
+	switch {
+	case os.Getenv("NO_COLOR") != "" || Dumb:
+		return ColorsNone
+
+	case colorterm == "truecolor" || colorterm == "24bit":
+		return ColorsTrue
+
+	case strings.Contains(term, "256color"):
+		return Colors256
+
+	case term == "linux" ||
+		strings.HasPrefix(term, "vt") ||
+		strings.HasPrefix(term, "ansi") ||
+		strings.HasPrefix(term, "cons"):
+		return Colors16
+	}
+
+	return Colors256
+}
+
+func ParseColor(
+	str string,
+) (Color, error) {
+	var (
+		err    error
+		index  uint64
+		rgb    uint64
+		name   = strings.ToLower(strings.TrimSpace(str))
+		bright = false
+	)
+
+	if name == "default" || name == "" {
+		return Color{}, nil
+	}
+
+	// "#rgb" is short for "#rrggbb"
+	if strings.HasPrefix(name, "#") && len(name) == 4 {
+		name = string([]byte{'#',
+			name[1], name[1],
+			name[2], name[2],
+			name[3], name[3]})
+	}
+
+	if strings.HasPrefix(name, "#") && len(name) == 7 {
+		rgb, err = strconv.ParseUint(name[1:], 16, 32)
+		if err == nil {
+			return Color{
+				Active: true,
+				R:      uint(rgb >> 16 & 0xff),
+				G:      uint(rgb >> 8 & 0xff),
+				B:      uint(rgb & 0xff),
+			}, nil
+		}
+	}
+
+	index, err = strconv.ParseUint(name, 10, 8)
+	if err == nil {
+		return indexedColor(uint(index)), nil
+	}
+
+	if name == "gray" || name == "grey" {
+		return indexedColor(8), nil
+	}
+
+	if IsRole(name) {
+		return Color{Active: true, Role: name}, nil
+	}
+
+	if strings.HasPrefix(name, "bright") {
+		bright = true
+		name = strings.TrimPrefix(name[len("bright"):], "-")
+	}
+
+	for i, v := range colorNames {
+		if v == name {
+			if bright {
+				i += 8
+			}
+			return indexedColor(uint(i)), nil
+		}
+	}
+
+	return Color{}, fmt.Errorf(`Unknown color "%v"`, str)
+}
+
+func IsRole(
+	name string,
+) bool {
+	for _, v := range Roles {
+		if v == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *Color) UnmarshalJSON(
+	data []byte,
+) error {
+	var (
+		err   error
+		index uint
+		obj   struct {
+			Active  bool
+			R, G, B uint
+			Indexed bool
+			Index   uint
+			Role    string
+		}
+		str   string
+	)
+
+	switch {
+	case len(data) > 0 && data[0] == '"':
+		err = json.Unmarshal(data, &str)
+		if err == nil {
+			*c, err = ParseColor(str)
+		}
+
+	case len(data) > 0 && data[0] == '{':
+		err = json.Unmarshal(data, &obj)
+		*c = Color(obj)
+		if err == nil && c.Role != "" && IsRole(c.Role) == false {
+			err = fmt.Errorf(`Unknown color role "%v"`, c.Role)
+		}
+
+	default:
+		err = json.Unmarshal(data, &index)
+		if err == nil && index > 255 {
+			err = fmt.Errorf(`Color index "%v" is not within 0 and 255`, index)
+		}
+		*c = indexedColor(index)
+	}
+
+	return err
+}
+
+func (c *FgColor) UnmarshalJSON(
+	data []byte,
+) error {
+	return (*Color)(c).UnmarshalJSON(data)
+}
+
+func (c *BgColor) UnmarshalJSON(
+	data []byte,
+) error {
+	return (*Color)(c).UnmarshalJSON(data)
 }
 
 func (c FgColor) String(
 ) string {
-	if c.Active {
-		return fmt.Sprintf("\x1b[38;2;%v;%v;%vm", c.R, c.G, c.B)
+	return Color(c).sgr(30, 90, "38")
+}
+
+func (c BgColor) String(
+) string {
+	return Color(c).sgr(40, 100, "48")
+}
+
+func indexedColor(
+	index uint,
+) Color {
+	var ret = Color{
+		Active:  true,
+		Indexed: true,
+		Index:   index,
+	}
+
+	ret.R, ret.G, ret.B = paletteRGB(index)
+
+	return ret
+}
+
+func nearest16(
+	r, g, b uint,
+) uint {
+	var (
+		best     uint = 0
+		bestDist = -1
+		dist     int
+	)
+
+	for i, v := range palette16 {
+		dist = rgbDist(r, g, b, v[0], v[1], v[2])
+		if bestDist < 0 || dist < bestDist {
+			best = uint(i)
+			bestDist = dist
+		}
+	}
+
+	return best
+}
+
+func nearest256(
+	r, g, b uint,
+) uint {
+	var (
+		cube                [3]uint
+		cubeR, cubeG, cubeB uint
+		grey                uint
+		greyR, greyG, greyB uint
+		level               uint
+	)
+
+	for i, v := range []uint{r, g, b} {
+		for j := range cubeLevels {
+			if rgbDist(v, 0, 0, cubeLevels[j], 0, 0) <
+				rgbDist(v, 0, 0, cubeLevels[cube[i]], 0, 0) {
+				cube[i] = uint(j)
+			}
+		}
+	}
+	cubeR, cubeG, cubeB = cubeLevels[cube[0]], cubeLevels[cube[1]], cubeLevels[cube[2]]
+
+	level = (r + g + b) / 3
+	if level < 8 {
+		grey = 232
+	} else if level > 238 {
+		grey = 255
 	} else {
-		return FgDefault
+		grey = 232 + (level - 8 + 5) / 10
 	}
+	greyR, greyG, greyB = paletteRGB(grey)
+
+	if rgbDist(r, g, b, greyR, greyG, greyB) <
+		rgbDist(r, g, b, cubeR, cubeG, cubeB) {
+		return grey
+	}
+
+	return 16 + 36 * cube[0] + 6 * cube[1] + cube[2]
 }
 
-type BgColor struct {
-	Active bool
-	R, G, B uint
+func paletteRGB(
+	index uint,
+) (uint, uint, uint) {
+	switch {
+	case index < 16:
+		return palette16[index][0], palette16[index][1], palette16[index][2]
+
+	case index < 232:
+		index -= 16
+		return cubeLevels[index / 36],
+			cubeLevels[index / 6 % 6],
+			cubeLevels[index % 6]
+	}
+
+	index = 8 + 10 * (index - 232)
+	return index, index, index
 }
 
-func (c BgColor) String(
+func rgbDist(
+	r1, g1, b1 uint,
+	r2, g2, b2 uint,
+) int {
+	var (
+		dr = int(r1) - int(r2)
+		dg = int(g1) - int(g2)
+		db = int(b1) - int(b2)
+	)
+
+	return dr * dr + dg * dg + db * db
+}
+
+// sgr returns the sequence setting the color, in the colors of ColorDepth.
+// base and brightBase are the codes of the 16 colors,
+// ext introduces 256 and true colors.
+func (c Color) sgr(
+	base       uint,
+	brightBase uint,
+	ext        string,
 ) string {
-	if c.Active {
-		return fmt.Sprintf("\x1b[48;2;%v;%v;%vm", c.R, c.G, c.B)
-	} else {
-		return BgDefault
+	var index uint
+
+	if c.Role != "" {
+		c = Palette[c.Role]
+	}
+
+	if ColorDepth == ColorsNone {
+		return ""
+	}
+
+	if c.Active == false {
+		// the default color
+		return fmt.Sprintf("\x1b[%vm", base + 9)
+	}
+
+	switch {
+	case c.Indexed && c.Index < 16:
+		index = c.Index
+
+	case ColorDepth <= Colors16:
+		index = nearest16(c.R, c.G, c.B)
+
+	case c.Indexed:
+		return fmt.Sprintf("\x1b[%v;5;%vm", ext, c.Index)
+
+	case ColorDepth <= Colors256:
+		return fmt.Sprintf("\x1b[%v;5;%vm", ext, nearest256(c.R, c.G, c.B))
+
+	default:
+		return fmt.Sprintf("\x1b[%v;2;%v;%v;%vm", ext, c.R, c.G, c.B)
+	}
+
+	if index >= 8 {
+		return fmt.Sprintf("\x1b[%vm", brightBase + index - 8)
 	}
+	return fmt.Sprintf("\x1b[%vm", base + index)
 }
@@ -5,6 +5,7 @@ package csi
 
 import (
 	"fmt"
+	"os"
 )
 
 const (
@@ -13,6 +14,8 @@ const (
 
 	Backspace = "\x7f"
 
+	AltScreenEnter = "\033[?1049h"
+	AltScreenLeave = "\033[?1049l"
 	Clear = "\033[H\033[2J"
 	CursorHide  = "\033[?25l"
 	CursorShow  = "\033[?25h"
@@ -28,22 +31,18 @@ const (
 	End = "\x1b[F"
 	FgDefault = "\033[39m"
 	BgDefault = "\033[49m"
+	Reset = "\033[0m"
 )
 
-func SetCursor(
-	x int,
-	y int,
-) {
-	fmt.Printf("\033[%v;%vH", y, x)
-}
+// Dumb terminals understand no escape sequences.
+var Dumb = os.Getenv("TERM") == "dumb"
 
-func SetCursorAligned(
+func AlignX(
 	alignment string,
 	rowLen int,
 	termW int,
 	x int,
-	y int,
-) {
+) int {
 	switch alignment {
 	case "left":
 		// nothing
@@ -60,5 +59,40 @@ func SetCursorAligned(
 		panic(fmt.Sprintf(`Unknown alignment "%v"`, alignment))
 	}
 
-	SetCursor(x, y)
+	return x
+}
+
+func CursorTo(
+	x int,
+	y int,
+) string {
+	return fmt.Sprintf("\033[%v;%vH", y, x)
+}
+
+// Send prints the escape sequence seq, unless the terminal is dumb.
+func Send(
+	seq string,
+) {
+	if Dumb {
+		return
+	}
+
+	fmt.Print(seq)
+}
+
+func SetCursor(
+	x int,
+	y int,
+) {
+	fmt.Print(CursorTo(x, y))
+}
+
+func SetCursorAligned(
+	alignment string,
+	rowLen int,
+	termW int,
+	x int,
+	y int,
+) {
+	SetCursor(AlignX(alignment, rowLen, termW, x), y)
 }
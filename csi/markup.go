@@ -0,0 +1,178 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+// Copyright (C) 2024 - 2025  Andy Frank Schoknecht
+
+package csi
+
+import (
+	"strings"
+)
+
+// A Span is a piece of text in one style.
+type Span struct {
+	Text  string
+	Style Style
+}
+
+var attrTags = map[string]Attrs{
+	"b": AttrBold,
+	"d": AttrDim,
+	"i": AttrItalic,
+	"u": AttrUnderline,
+	"r": AttrReverse,
+	"s": AttrStrikethrough,
+}
+
+// ParseMarkup splits str into spans, starting with the style base.
+// The tags "[b]", "[d]", "[i]", "[u]", "[r]" and "[s]",
+// or the full names of the attributes, add an attribute.
+// "[fg=COLOR]" and "[bg=COLOR]" set a color,
+// and "{ROLE}" is short for "[fg=ROLE]".
+// "[/]" ends the last tag, and is kept as text when no tag is open.
+// "[[" and "{{" are a literal "[" and "{".
+// Anything else in brackets or braces is kept as text.
+func ParseMarkup(
+	str  string,
+	base Style,
+) []Span {
+	var (
+		end   int
+		ok    bool
+		ret   []Span
+		stack = []Style{base}
+		style Style
+		tag   string
+		text  strings.Builder
+	)
+
+	for i := 0; i < len(str); i++ {
+		if strings.HasPrefix(str[i:], "[[") || strings.HasPrefix(str[i:], "{{") {
+			text.WriteByte(str[i])
+			i++
+			continue
+		}
+
+		ok = false
+		switch str[i] {
+		case '[':
+			end = strings.IndexByte(str[i:], ']')
+			if end > 0 {
+				tag = str[i+1 : i+end]
+				style, ok = applyTag(stack[len(stack) - 1], tag)
+				if tag == "/" && len(stack) <= 1 {
+					ok = false
+				}
+			}
+
+		case '{':
+			end = strings.IndexByte(str[i:], '}')
+			if end > 0 && IsRole(str[i+1 : i+end]) {
+				tag = "fg=" + str[i+1 : i+end]
+				style, ok = applyTag(stack[len(stack) - 1], tag)
+			}
+		}
+
+		if ok == false {
+			text.WriteByte(str[i])
+			continue
+		}
+
+		if text.Len() > 0 {
+			ret = append(ret, Span{
+				Text:  text.String(),
+				Style: stack[len(stack) - 1],
+			})
+			text.Reset()
+		}
+
+		if tag == "/" {
+			stack = stack[:len(stack) - 1]
+		} else {
+			stack = append(stack, style)
+		}
+		i += end
+	}
+
+	if text.Len() > 0 {
+		ret = append(ret, Span{
+			Text:  text.String(),
+			Style: stack[len(stack) - 1],
+		})
+	}
+
+	return ret
+}
+
+// Markup returns str with its tags replaced by escape sequences,
+// going back to the style base at the end.
+func Markup(
+	str  string,
+	base Style,
+) string {
+	var (
+		b    strings.Builder
+		prev = base
+	)
+
+	for _, v := range ParseMarkup(str, base) {
+		if v.Style != prev {
+			b.WriteString(v.Style.String())
+			prev = v.Style
+		}
+		b.WriteString(v.Text)
+	}
+
+	if prev != base {
+		b.WriteString(base.String())
+	}
+
+	return b.String()
+}
+
+// StripMarkup returns the text of str without its tags.
+func StripMarkup(
+	str string,
+) string {
+	var b strings.Builder
+
+	for _, v := range ParseMarkup(str, Style{}) {
+		b.WriteString(v.Text)
+	}
+
+	return b.String()
+}
+
+// applyTag returns style changed by tag,
+// and whether tag is known, "/" being known but changing nothing.
+func applyTag(
+	style Style,
+	tag   string,
+) (Style, bool) {
+	var (
+		attr  Attrs
+		color Color
+		err   error
+		found bool
+	)
+
+	switch {
+	case tag == "/":
+		return style, true
+
+	case strings.HasPrefix(tag, "fg="):
+		color, err = ParseColor(tag[len("fg="):])
+		style.Fg = FgColor(color)
+
+	case strings.HasPrefix(tag, "bg="):
+		color, err = ParseColor(tag[len("bg="):])
+		style.Bg = BgColor(color)
+
+	default:
+		attr, found = attrTags[tag]
+		if found == false {
+			attr, err = ParseAttr(tag)
+		}
+		style.Attrs |= attr
+	}
+
+	return style, err == nil
+}
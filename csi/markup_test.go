@@ -0,0 +1,50 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+// Copyright (C) 2024 - 2025  Andy Frank Schoknecht
+
+package csi
+
+import (
+	"testing"
+)
+
+func TestStripMarkup(
+	t *testing.T,
+) {
+	var (
+		got   string
+		tests = []struct {
+			In   string
+			Want string
+		}{
+			{"a [fg=#ff0]warn[/] b", "a warn b"},
+			{"a [fg=#ffff00]warn[/] b", "a warn b"},
+			{"a [/] b", "a [/] b"},
+			{"[b]a[/][/]", "a[/]"},
+			{"[nope]a[/]", "[nope]a[/]"},
+		}
+	)
+
+	for _, v := range tests {
+		got = StripMarkup(v.In)
+		if got != v.Want {
+			t.Errorf("StripMarkup(%q) = %q, want %q", v.In, got, v.Want)
+		}
+	}
+}
+
+func TestParseColorShortHex(
+	t *testing.T,
+) {
+	var (
+		c   Color
+		err error
+	)
+
+	c, err = ParseColor("#f80")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.R != 0xff || c.G != 0x88 || c.B != 0 {
+		t.Errorf(`ParseColor("#f80") = %+v`, c)
+	}
+}
@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+// Copyright (C) 2024 - 2025  Andy Frank Schoknecht
+
+package csi
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	AttrBold Attrs = 1 << iota
+	AttrDim
+	AttrItalic
+	AttrUnderline
+	AttrReverse
+	AttrStrikethrough
+)
+
+// Attrs is a set of text attributes.
+// In configs it is written as a list of names, like ["bold", "reverse"].
+type Attrs uint
+
+type Style struct {
+	Fg    FgColor
+	Bg    BgColor
+	Attrs Attrs
+}
+
+var attrNames = []struct {
+	Name string
+	Attr Attrs
+	Code string
+}{
+	{"bold", AttrBold, "1"},
+	{"dim", AttrDim, "2"},
+	{"italic", AttrItalic, "3"},
+	{"underline", AttrUnderline, "4"},
+	{"reverse", AttrReverse, "7"},
+	{"strikethrough", AttrStrikethrough, "9"},
+}
+
+func ParseAttr(
+	str string,
+) (Attrs, error) {
+	var name = strings.ToLower(strings.TrimSpace(str))
+
+	for _, v := range attrNames {
+		if v.Name == name {
+			return v.Attr, nil
+		}
+	}
+
+	return 0, fmt.Errorf(`Unknown text attribute "%v"`, str)
+}
+
+func (a *Attrs) UnmarshalJSON(
+	data []byte,
+) error {
+	var (
+		attr  Attrs
+		err   error
+		names []string
+	)
+
+	err = json.Unmarshal(data, &names)
+	if err != nil {
+		return err
+	}
+
+	*a = 0
+	for _, v := range names {
+		attr, err = ParseAttr(v)
+		if err != nil {
+			return err
+		}
+		*a |= attr
+	}
+
+	return nil
+}
+
+// String returns the sequence that resets all attributes and sets these.
+func (a Attrs) String(
+) string {
+	var b strings.Builder
+
+	if Dumb {
+		return ""
+	}
+
+	b.WriteString("\x1b[0")
+	for _, v := range attrNames {
+		if a & v.Attr != 0 {
+			b.WriteString(";" + v.Code)
+		}
+	}
+	b.WriteString("m")
+
+	return b.String()
+}
+
+// String returns the sequence that sets the whole style,
+// so nothing of a previous style is left over.
+func (s Style) String(
+) string {
+	return s.Attrs.String() + s.Fg.String() + s.Bg.String()
+}
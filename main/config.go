@@ -61,8 +61,17 @@ Use only a "Shell" or a "ShellSession" value or a "Menu" value`,
 	}
 }
 
+// Menu layouts, an empty layout is a list.
+// Columns are filled from top to bottom, a grid from left to right.
+const (
+	menuList    = "list"
+	menuColumns = "columns"
+	menuGrid    = "grid"
+)
+
 type menu struct {
 	Title   string
+	Layout  string
 	Entries []entry
 }
 
@@ -75,6 +84,18 @@ func (m menu) validate(
 		panic(fmt.Sprintf(`Menu "%v" has no entries`, menuIndex))
 	}
 
+	switch m.Layout {
+	case "":
+	case menuList:
+	case menuColumns:
+	case menuGrid:
+
+	default:
+		panic(fmt.Sprintf(`Unknown layout "%v" of menu "%v"`,
+			m.Layout,
+			menuIndex))
+	}
+
 	for _, e := range m.Entries {
 		e.validate(fnMap, menus)
 	}
@@ -86,7 +107,7 @@ type eventsConfig struct {
 }
 
 type entryConfig struct {
-	Alignment                string
+	common.RegionConfig
 	MenuPrefix               string
 	MenuPostfix              string
 	MenuHoverPrefix          string
@@ -103,14 +124,17 @@ type entryConfig struct {
 	GoPostfix                string
 	GoHoverPrefix            string
 	GoHoverPostfix           string
-	Fg                       csi.FgColor
-	Bg                       csi.BgColor
+	Wrap                     string
+	WrapIndent               int
+	Columns                  int
+	ColumnGap                int
 	HoverFg                  csi.FgColor
 	HoverBg                  csi.BgColor
+	HoverAttrs               csi.Attrs
 }
 
 type keysConfig struct {
-	Execute string
+	Execute string `help:"execute, in menus with columns also go into"`
 }
 
 type pagerConfig struct {
@@ -118,12 +142,13 @@ type pagerConfig struct {
 }
 
 type huiConfig struct {
-	Header string
-	Pager  pagerConfig
-	Keys   keysConfig
-	Entry  entryConfig
-	Events eventsConfig
-	Menus  map[string]menu
+	Header   string
+	Commands []common.CommandConfig
+	Pager    pagerConfig
+	Keys     keysConfig
+	Entry    entryConfig
+	Events   eventsConfig
+	Menus    map[string]menu
 }
 
 func huiConfigFromFile(
@@ -136,6 +161,14 @@ func huiConfigFromFile(
 	common.AnyConfigFromFile(&ret, "hui.json", cfgPath)
 
 	ret.validateAlignments()
+	ret.validateWraps()
+	ret.Entry.Validate()
+	if ret.Entry.Columns < 0 || ret.Entry.ColumnGap < 0 {
+		panic(fmt.Sprintf(`Invalid columns "%v" or column gap "%v" in config`,
+			ret.Entry.Columns,
+			ret.Entry.ColumnGap))
+	}
+	common.ValidateCommands(ret.Commands)
 	ret.validateMenus(fnMap)
 	if ret.Events.Start != "" {
 		validateGo(ret.Events.Start, fnMap)
@@ -152,6 +185,11 @@ func (c huiConfig) validateAlignments(
 	common.ValidateAlignment(c.Entry.Alignment)
 }
 
+func (c huiConfig) validateWraps(
+) {
+	common.ValidateWrap(c.Entry.Wrap, c.Entry.WrapIndent)
+}
+
 func (c huiConfig) validateMenus(
 	fnMap common.ScriptFnMap,
 ) {
@@ -10,11 +10,14 @@ import (
 	"fmt"
 	"golang.org/x/term"
 	"os"
+	"strings"
 )
 
+// Offset is the first entry in view.
 type menuPathNode struct {
 	Cursor int
 	Menu string
+	Offset int
 }
 
 type menuPath []menuPathNode
@@ -24,13 +27,56 @@ func (mp menuPath) curCursor(
 	return &mp[len(mp) - 1].Cursor
 }
 
+func (mp menuPath) curOffset(
+) *int {
+	return &mp[len(mp) - 1].Offset
+}
+
 func (mp menuPath) curMenu(
 ) string {
 	return mp[len(mp)-1].Menu
 }
 
+// crumbs returns the name or the first line of the title
+// of each menu in the path, for the breadcrumb.
+func (mp menuPath) crumbs(
+	menus  map[string]menu,
+	source string,
+) []string {
+	var (
+		ret   = make([]string, len(mp))
+		title string
+	)
+
+	for i, v := range mp {
+		title = strings.TrimSpace(
+			strings.SplitN(menus[v.Menu].Title, "\n", 2)[0])
+
+		if source == "name" || csi.StripMarkup(title) == "" {
+			ret[i] = v.Menu
+		} else {
+			ret[i] = title
+		}
+	}
+
+	return ret
+}
+
+// String returns the names of the menus, separated by slashes.
+func (mp menuPath) String(
+) string {
+	var names = make([]string, len(mp))
+
+	for i, v := range mp {
+		names[i] = v.Menu
+	}
+
+	return strings.Join(names, "/")
+}
+
 type appData struct {
 	common.ComAppData
+	ContentHeight     int
 	HuiCfg            huiConfig
 	MPath             menuPath
 }
@@ -50,6 +96,9 @@ Options:
     -h --help
         prints this message then exits
 
+    --no-color
+        shows no colors, like when NO_COLOR is set
+
     -v --version
         prints version information then exits
 
@@ -68,14 +117,20 @@ Default keybinds:
         go up
 
     l
-        go into
+        go into, in menus with columns only from the last column
 
     L
-        execute
+        execute, in menus with columns also go into
 
     :
         enter the internal command line
 
+    Q *register*
+        record keys into the register, until Q is pressed again
+
+    @ *register*
+        play the keys recorded in the register
+
 Internal commands:
 
     q quit exit
@@ -84,6 +139,36 @@ Internal commands:
     *number*
         when given a positive number, it is used as a line number to scroll to
 
+    map [keys] [sequence]
+        maps keys to a key sequence (e.g. "map x :sh date<CR>"),
+        lists all mappings when given no arguments
+
+    unmap *keys*
+        removes a key mapping
+
+    source *file*
+        runs each line of the given file as an internal command
+
+    help [topic]
+        opens the help in the pager, optionally only the given topic
+
+    sh *command*
+        runs the given shell command and shows its output as feedback
+
+    shs *command*
+        runs the given shell command as an interactive session
+
+    Further commands can be declared via "Commands" in common.json and hui.json.
+
+Files:
+
+    huirc
+        internal commands run on startup, one per line,
+        searched for in the same directories as the config files
+
+    $XDG_STATE_HOME/hui/ or ~/.local/state/hui/
+        recorded key macros are kept here
+
 Environmental variables:
 
     PAGER
@@ -99,86 +184,522 @@ var (
 	AppVersion    string
 )
 
+func draw(
+	ad *appData,
+) {
+	var (
+		curMenu menu
+		err error
+		region = ad.HuiCfg.Entry.Region()
+		termH, termW int
+	)
+
+	termW, termH, err = term.GetSize(int(os.Stdin.Fd()))
+	if err != nil {
+		panic(fmt.Sprintf("Could not get term size:\n%v", err))
+	}
+	ad.Scr.Resize(termW, termH)
+	ad.Scr.Clear()
+	curMenu = ad.HuiCfg.Menus[ad.MPath.curMenu()]
+	ad.MenuPath = ad.MPath.String()
+	ad.Breadcrumbs = ad.MPath.crumbs(ad.HuiCfg.Menus,
+		ad.ComCfg.Breadcrumb.Source)
+
+	ad.DrawLayout(ad.HuiCfg.Header,
+		curMenu.Title,
+		ad.HuiCfg.Pager.Title,
+		func(w int) int {
+			var (
+				n     = 0
+				table [][]string
+			)
+
+			w -= ad.ComCfg.Scroll.BarWidth()
+			table, _, _ = menuTable(region.InnerWidth(w),
+				curMenu,
+				-1,
+				ad.HuiCfg)
+			for _, v := range table {
+				n += len(v)
+			}
+
+			return region.Height(n)
+		},
+		func(scr *common.Screen) {
+			var (
+				barW = ad.ComCfg.Scroll.BarWidth()
+				begin, n, total int
+			)
+
+			ad.ContentHeight = scr.H - region.VSpace()
+			if ad.ContentHeight < 1 {
+				ad.ContentHeight = 1
+			}
+			begin, n, total = drawMenu(scr.Sub(0, 0, scr.W - barW, scr.H),
+				0,
+				ad.ContentHeight,
+				curMenu,
+				*ad.MPath.curCursor(),
+				ad.MPath.curOffset(),
+				ad.HuiCfg,
+				ad.ComCfg)
+			common.DrawScrollbar(scr,
+				ad.ComCfg,
+				region.TopSpace(),
+				ad.ContentHeight,
+				begin,
+				n,
+				total)
+		})
+
+	ad.Scr.Flush()
+}
+
+// drawMenu moves the offset to follow the cursor,
+// and returns the first row shown, how many rows are shown,
+// and how many rows there are.
+// A row holds one entry, or one row of entries in columns or a grid.
 func drawMenu(
+	scr *common.Screen,
+	y int,
 	contentHeight int,
 	curMenu menu,
 	cursor int,
+	offset *int,
 	huicfg huiConfig,
-	termW int,
-) {
+	comcfg common.ComConfig,
+) (int, int, int) {
 	var (
+		above, below    bool
+		cols            int
+		cursorRow       int
 		drawBegin       int
-		drawEnd         int
-		prefix, postfix string
-		fg              csi.FgColor
-		bg              csi.BgColor
+		lines           []string
+		n               int
+		region          = huicfg.Entry.Region()
+		rows            []int
+		scrollStyle     = comcfg.Scroll.Style()
+		style           csi.Style
+		styles          []csi.Style
+		table           [][]string
 	)
 
-	if len(curMenu.Entries) > contentHeight {
-		drawBegin = cursor
-		drawEnd = cursor + contentHeight
-		if drawEnd > len(curMenu.Entries) {
-			drawEnd = len(curMenu.Entries)
+	table, cols, cursorRow = menuTable(region.InnerWidth(scr.W),
+		curMenu,
+		cursor,
+		huicfg)
+	rows = make([]int, len(table))
+	for i, v := range table {
+		rows[i] = len(v)
+	}
+
+	comcfg.Scroll.Follow(offset, cursorRow, rows, contentHeight)
+	drawBegin = *offset
+	n, above, below = comcfg.Scroll.Fit(rows, drawBegin, contentHeight)
+
+	if above {
+		lines = append(lines,
+			comcfg.Scroll.Marker(comcfg.Scroll.MarkerAbove,
+				curMenu.entriesBefore(drawBegin, cols)))
+		styles = append(styles, scrollStyle)
+	}
+
+	for i := drawBegin; i < drawBegin + n; i++ {
+		// the hovered cell of a table brings its own style
+		style = entryStyle(i == cursorRow && curMenu.isTable() == false,
+			huicfg)
+
+		for _, v := range table[i] {
+			if len(lines) >= contentHeight {
+				break
+			}
+			lines = append(lines, v)
+			styles = append(styles, style)
 		}
-	} else {
-		drawBegin = 0
-		drawEnd = len(curMenu.Entries)
 	}
 
-	for i := drawBegin; i < len(curMenu.Entries) && i < drawEnd; i++ {
-		hover := i == cursor
+	if below {
+		if len(lines) >= contentHeight {
+			lines = lines[:contentHeight - 1]
+			styles = styles[:contentHeight - 1]
+		}
+		lines = append(lines,
+			comcfg.Scroll.Marker(comcfg.Scroll.MarkerBelow,
+				len(curMenu.Entries) -
+				curMenu.entriesBefore(drawBegin + n, cols)))
+		styles = append(styles, scrollStyle)
+	}
 
-		if curMenu.Entries[i].Shell != "" {
-			if hover {
-				prefix = huicfg.Entry.ShellHoverPrefix
-				postfix = huicfg.Entry.ShellHoverPostfix
-			} else {
-				prefix = huicfg.Entry.ShellPrefix
-				postfix = huicfg.Entry.ShellPostfix
+	scr.PrintRegion(region, y, lines, styles)
+
+	return drawBegin, n, len(table)
+}
+
+// menuTable returns the lines of each row of the menu, wrapped to w,
+// how many columns there are, and the row of the cursor.
+func menuTable(
+	w int,
+	m menu,
+	cursor int,
+	huicfg huiConfig,
+) ([][]string, int, int) {
+	var (
+		cells     [][]string
+		cellW     int
+		cols      int
+		cursorRow = 0
+		height    int
+		hover     = entryStyle(true, huicfg)
+		i         int
+		line      strings.Builder
+		normal    = entryStyle(false, huicfg)
+		ret       [][]string
+		rows      int
+		text      string
+	)
+
+	if m.isTable() == false {
+		for i, v := range m.Entries {
+			ret = append(ret, entryLines(w, v, i == cursor, huicfg))
+		}
+		return ret, 1, cursor
+	}
+
+	cellW, cols = m.columns(w, huicfg)
+	rows = tableRows(len(m.Entries), cols)
+	ret = make([][]string, rows)
+
+	for r := 0; r < rows; r++ {
+		cells = make([][]string, cols)
+		height = 1
+		for c := 0; c < cols; c++ {
+			i = m.cellIndex(r, c, cols)
+			if i >= len(m.Entries) {
+				continue
 			}
-		} else if curMenu.Entries[i].ShellSession != "" {
-			if hover {
-				prefix = huicfg.Entry.ShellSessionHoverPrefix
-				postfix = huicfg.Entry.ShellSessionHoverPostfix
-			} else {
-				prefix = huicfg.Entry.ShellSessionPrefix
-				postfix = huicfg.Entry.ShellSessionPostfix
+			if i == cursor {
+				cursorRow = r
 			}
-		} else if curMenu.Entries[i].Go != "" {
-			if hover {
-				prefix = huicfg.Entry.GoHoverPrefix
-				postfix = huicfg.Entry.GoHoverPostfix
-			} else {
-				prefix = huicfg.Entry.GoPrefix
-				postfix = huicfg.Entry.GoPostfix
+			cells[c] = entryLines(cellW, m.Entries[i], i == cursor, huicfg)
+			if len(cells[c]) > height {
+				height = len(cells[c])
 			}
-		} else {
-			if hover {
-				prefix = huicfg.Entry.MenuHoverPrefix
-				postfix = huicfg.Entry.MenuHoverPostfix
-			} else {
-				prefix = huicfg.Entry.MenuPrefix
-				postfix = huicfg.Entry.MenuPostfix
+		}
+
+		for l := 0; l < height; l++ {
+			line.Reset()
+			for c := 0; c < cols; c++ {
+				if c > 0 {
+					line.WriteString(strings.Repeat(" ",
+						huicfg.Entry.ColumnGap))
+				}
+
+				text = ""
+				if l < len(cells[c]) {
+					text = cells[c][l]
+				}
+				text += strings.Repeat(" ", cellW - common.TextWidth(text))
+
+				if m.cellIndex(r, c, cols) == cursor {
+					text = hover.String() + text + normal.String()
+				}
+				line.WriteString(text)
 			}
+			ret[r] = append(ret[r], line.String())
+		}
+	}
+
+	return ret, cols, cursorRow
+}
+
+// columns returns the width of the cells,
+// and how many columns of them fit into w.
+func (m menu) columns(
+	w int,
+	huicfg huiConfig,
+) (int, int) {
+	var (
+		cellW = 1
+		cols  int
+		gap   = huicfg.Entry.ColumnGap
+	)
+
+	for _, v := range m.Entries {
+		for _, hover := range []bool{false, true} {
+			for _, l := range entryLines(w, v, hover, huicfg) {
+				if common.TextWidth(l) > cellW {
+					cellW = common.TextWidth(l)
+				}
+			}
+		}
+	}
+
+	cols = (w + gap) / (cellW + gap)
+	if huicfg.Entry.Columns > 0 && cols > huicfg.Entry.Columns {
+		cols = huicfg.Entry.Columns
+	}
+	if cols > len(m.Entries) {
+		cols = len(m.Entries)
+	}
+	if cols < 1 {
+		cols = 1
+	}
+
+	// columns are filled from the top, so spare columns are dropped
+	if m.Layout == menuColumns {
+		cols = tableRows(len(m.Entries), tableRows(len(m.Entries), cols))
+	}
+
+	return cellW, cols
+}
+
+// cellIndex returns the entry at row r and column c of the table.
+func (m menu) cellIndex(
+	r    int,
+	c    int,
+	cols int,
+) int {
+	if m.Layout == menuColumns {
+		return c * tableRows(len(m.Entries), cols) + r
+	}
+
+	return r * cols + c
+}
+
+// entriesBefore returns how many entries are in the rows above row.
+func (m menu) entriesBefore(
+	row  int,
+	cols int,
+) int {
+	var ret = 0
+
+	if m.isTable() == false {
+		return row
+	}
+
+	for i := range m.Entries {
+		if m.cellRow(i, cols) < row {
+			ret++
+		}
+	}
+
+	return ret
+}
+
+func (m menu) cellRow(
+	i    int,
+	cols int,
+) int {
+	if m.Layout == menuColumns {
+		return i % tableRows(len(m.Entries), cols)
+	}
+
+	return i / cols
+}
+
+func (m menu) cellColumn(
+	i    int,
+	cols int,
+) int {
+	if m.Layout == menuColumns {
+		return i / tableRows(len(m.Entries), cols)
+	}
+
+	return i % cols
+}
+
+func (m menu) isTable(
+) bool {
+	return m.Layout == menuColumns || m.Layout == menuGrid
+}
+
+// moveCursor returns the entry dx columns and dy rows away from cursor,
+// and whether there is an entry.
+func (m menu) moveCursor(
+	cursor int,
+	dx     int,
+	dy     int,
+	cols   int,
+) (int, bool) {
+	var (
+		c    int
+		i    int
+		r    int
+		rows int
+	)
+
+	if m.isTable() == false {
+		cols = 1
+	}
+	rows = tableRows(len(m.Entries), cols)
+
+	r = m.cellRow(cursor, cols) + dy
+	c = m.cellColumn(cursor, cols) + dx
+	if r < 0 || r >= rows || c < 0 || c >= cols {
+		return cursor, false
+	}
+
+	i = m.cellIndex(r, c, cols)
+	if i >= len(m.Entries) {
+		return cursor, false
+	}
+
+	return i, true
+}
+
+// pageCursor returns the entry up to rows rows away from cursor,
+// in the same column, going down for positive rows.
+func (m menu) pageCursor(
+	cursor int,
+	rows   int,
+	cols   int,
+) int {
+	var (
+		moved bool
+		next  int
+		step  = 1
+	)
+
+	if rows < 0 {
+		step = -1
+		rows = -rows
+	}
+
+	for i := 0; i < rows; i++ {
+		next, moved = m.moveCursor(cursor, 0, step, cols)
+		if moved == false {
+			break
 		}
+		cursor = next
+	}
 
+	return cursor
+}
+
+// tableRows returns how many rows n entries take in cols columns.
+func tableRows(
+	n    int,
+	cols int,
+) int {
+	return (n + cols - 1) / cols
+}
+
+func entryStyle(
+	hover  bool,
+	huicfg huiConfig,
+) csi.Style {
+	var ret csi.Style
+
+	if hover == false {
+		return csi.Style{
+			Fg:    huicfg.Entry.Fg,
+			Bg:    huicfg.Entry.Bg,
+			Attrs: huicfg.Entry.Attrs,
+		}
+	}
+
+	ret = csi.Style{
+		Fg:    huicfg.Entry.HoverFg,
+		Bg:    huicfg.Entry.HoverBg,
+		Attrs: huicfg.Entry.HoverAttrs,
+	}
+	// without colors, the hovered entry is shown reversed
+	if csi.ColorDepth == csi.ColorsNone && ret.Attrs == 0 {
+		ret.Attrs = csi.AttrReverse
+	}
+
+	return ret
+}
+
+// entryLines returns the caption of e with its pre- and postfix,
+// wrapped to w, with the markup of the caption applied.
+func entryLines(
+	w int,
+	e entry,
+	hover bool,
+	huicfg huiConfig,
+) []string {
+	var prefix, postfix string
+
+	if e.Shell != "" {
+		if hover {
+			prefix = huicfg.Entry.ShellHoverPrefix
+			postfix = huicfg.Entry.ShellHoverPostfix
+		} else {
+			prefix = huicfg.Entry.ShellPrefix
+			postfix = huicfg.Entry.ShellPostfix
+		}
+	} else if e.ShellSession != "" {
+		if hover {
+			prefix = huicfg.Entry.ShellSessionHoverPrefix
+			postfix = huicfg.Entry.ShellSessionHoverPostfix
+		} else {
+			prefix = huicfg.Entry.ShellSessionPrefix
+			postfix = huicfg.Entry.ShellSessionPostfix
+		}
+	} else if e.Go != "" {
+		if hover {
+			prefix = huicfg.Entry.GoHoverPrefix
+			postfix = huicfg.Entry.GoHoverPostfix
+		} else {
+			prefix = huicfg.Entry.GoPrefix
+			postfix = huicfg.Entry.GoPostfix
+		}
+	} else {
 		if hover {
-			fg = huicfg.Entry.HoverFg
-			bg = huicfg.Entry.HoverBg
+			prefix = huicfg.Entry.MenuHoverPrefix
+			postfix = huicfg.Entry.MenuHoverPostfix
 		} else {
-			fg = huicfg.Entry.Fg
-			bg = huicfg.Entry.Bg
+			prefix = huicfg.Entry.MenuPrefix
+			postfix = huicfg.Entry.MenuPostfix
 		}
+	}
+
+	return common.WrapText(w,
+		fmt.Sprintf("%v%v%v",
+			prefix,
+			csi.Markup(e.Caption, entryStyle(hover, huicfg)),
+			postfix),
+		huicfg.Entry.Wrap,
+		huicfg.Entry.WrapIndent)
+}
+
+// contentWidth returns the width the entries are wrapped to,
+// at the current size of the terminal.
+func (ad *appData) contentWidth(
+) int {
+	var (
+		err   error
+		termW int
+	)
 
-		common.Cprinta(huicfg.Entry.Alignment,
-			fg,
-			bg,
-			termW,
-			fmt.Sprintf("%v%v%v",
-				prefix,
-				curMenu.Entries[i].Caption,
-				postfix))
+	termW, _, err = term.GetSize(int(os.Stdin.Fd()))
+	if err != nil {
+		termW = ad.Scr.W
 	}
+
+	return ad.HuiCfg.Entry.Region().InnerWidth(
+		ad.ComCfg.Layout.ContentWidth(termW) - ad.ComCfg.Scroll.BarWidth())
+}
+
+// tablePage returns how many rows of the table of m make a page,
+// which are the rows shown whole from the current offset.
+func (ad *appData) tablePage(
+	m      menu,
+	height int,
+) int {
+	var (
+		rows  []int
+		table [][]string
+	)
+
+	table, _, _ = menuTable(ad.contentWidth(), m, -1, ad.HuiCfg)
+	rows = make([]int, len(table))
+	for i, v := range table {
+		rows[i] = len(v)
+	}
+
+	return ad.ComCfg.Scroll.Page(rows, *ad.MPath.curOffset(), height)
 }
 
 func handleArgs(
@@ -213,6 +734,11 @@ func handleArgs(
 			fmt.Printf(HELP)
 			return false
 
+		case "--no-color":
+			// also for the programs started from here
+			os.Setenv("NO_COLOR", "1")
+			csi.ColorDepth = csi.ColorsNone
+
 		case "-v":
 			fallthrough
 		case "--version":
@@ -227,40 +753,6 @@ func handleArgs(
 	return true
 }
 
-func handleInput(
-	contentHeight int,
-	cmdMap common.ScriptCmdMap,
-	fnMap common.ScriptFnMap,
-	ad *appData,
-) {
-	var (
-		canonicalState *term.State
-		err error
-		input string
-		rawInput = make([]byte, 4)
-		rawInputLen int
-	)
-
-	if ad.AcceptInput == false {
-		return
-	}
-
-	canonicalState, err = term.MakeRaw(int(os.Stdin.Fd()))
-	if err != nil {
-		panic(fmt.Sprintf("Switching to raw mode failed:\n%v", err))
-	}
-
-	rawInputLen, err = os.Stdin.Read(rawInput)
-	if err != nil {
-		panic(fmt.Sprintf("Reading from stdin failed:\n%v", err))
-	}
-	input = string(rawInput[0:rawInputLen])
-
-	term.Restore(int(os.Stdin.Fd()), canonicalState)
-
-	handleKey(string(input), cmdMap, contentHeight, fnMap, ad)
-}
-
 func handleKey(
 	key string,
 	cmdMap common.ScriptCmdMap,
@@ -272,6 +764,9 @@ func handleKey(
 		curCursor = ad.MPath.curCursor()
 		curMenu = ad.HuiCfg.Menus[ad.MPath.curMenu()]
 		curEntry = &curMenu.Entries[*curCursor]
+		cols int
+		moved bool
+		next int
 	)
 
 	if ad.CmdLine.Active {
@@ -286,36 +781,51 @@ func handleKey(
 		return
 	}
 
+	// the menu may have been entered since the last draw
+	if curMenu.isTable() {
+		_, cols = curMenu.columns(ad.contentWidth(), ad.HuiCfg)
+	}
+
 	switch key {
 	case ad.ComCfg.Keys.Quit:
 		ad.Active = false
 
+	// in columns and grids, left and right move between the columns,
+	// and only go back or into a menu at the edges
 	case csi.CursorLeft:
 		fallthrough
 	case ad.ComCfg.Keys.Left:
-		if len(ad.MPath) > 1 {
+		next, moved = curMenu.moveCursor(*curCursor, -1, 0, cols)
+		if moved {
+			*curCursor = next
+		} else if len(ad.MPath) > 1 {
 			ad.MPath = ad.MPath[:len(ad.MPath)-1]
 		}
 
 	case csi.CursorDown:
 		fallthrough
 	case ad.ComCfg.Keys.Down:
-		if *curCursor < len(curMenu.Entries)-1 {
-			*curCursor++
+		next, moved = curMenu.moveCursor(*curCursor, 0, 1, cols)
+		if moved {
+			*curCursor = next
 		}
 
 	case csi.CursorUp:
 		fallthrough
 	case ad.ComCfg.Keys.Up:
-		if *curCursor > 0 {
-			*curCursor--
+		next, moved = curMenu.moveCursor(*curCursor, 0, -1, cols)
+		if moved {
+			*curCursor = next
 		}
 
 	case csi.CursorRight:
 		fallthrough
 	case ad.ComCfg.Keys.Right:
-		if curEntry.Menu != "" {
-			ad.MPath = append(ad.MPath, menuPathNode{0, curEntry.Menu})
+		next, moved = curMenu.moveCursor(*curCursor, 1, 0, cols)
+		if moved {
+			*curCursor = next
+		} else if curEntry.Menu != "" {
+			ad.MPath = append(ad.MPath, menuPathNode{0, curEntry.Menu, 0})
 		} else {
 			ad.Fb = "Entry has no menu, can't open."
 		}
@@ -327,23 +837,33 @@ func handleKey(
 			ad.Fb = common.HandleShellSession(curEntry.ShellSession)
 		} else if curEntry.Go != "" {
 			fnMap[curEntry.Go]()
+		} else if curEntry.Menu != "" && curMenu.isTable() {
+			ad.MPath = append(ad.MPath, menuPathNode{0, curEntry.Menu, 0})
 		} else {
 			ad.Fb = "Entry has no shell or go, can't execute."
 		}
 
 	case ad.ComCfg.Keys.Cmdmode:
 		ad.CmdLine.Active = true
-		fmt.Printf(csi.CursorShow)
+		csi.Send(csi.CursorShow)
 
 	case csi.PgUp:
-		if *curCursor - contentHeight < 0 {
+		if curMenu.isTable() {
+			*curCursor = curMenu.pageCursor(*curCursor,
+				-ad.tablePage(curMenu, contentHeight),
+				cols)
+		} else if *curCursor - contentHeight < 0 {
 			*curCursor = 0
 		} else {
 			*curCursor -= contentHeight
 		}
 
 	case csi.PgDown:
-		if *curCursor + contentHeight >= len(curMenu.Entries) {
+		if curMenu.isTable() {
+			*curCursor = curMenu.pageCursor(*curCursor,
+				ad.tablePage(curMenu, contentHeight),
+				cols)
+		} else if *curCursor + contentHeight >= len(curMenu.Entries) {
 			*curCursor = len(curMenu.Entries) - 1
 		} else {
 			*curCursor += contentHeight
@@ -362,57 +882,6 @@ func handleKey(
 	}
 }
 
-func tick(
-	cmdMap common.ScriptCmdMap,
-	fnMap  common.ScriptFnMap,
-	ad     *appData,
-) {
-	var (
-		contentHeight int
-		curMenu menu
-		err error
-		headerLines []string
-		lower string
-		termH, termW int
-		titleLines []string
-	)
-
-	fmt.Print(csi.Clear)
-	termW, termH, err = term.GetSize(int(os.Stdin.Fd()))
-	if err != nil {
-		panic(fmt.Sprintf("Could not get term size:\n%v", err))
-	}
-	curMenu = ad.HuiCfg.Menus[ad.MPath.curMenu()]
-
-	headerLines = common.SplitByLines(termW, ad.HuiCfg.Header)
-	titleLines = common.SplitByLines(termW, curMenu.Title)
-	lower = common.GenerateLower(ad.CmdLine.Content,
-		ad.CmdLine.Active,
-		ad.ComCfg,
-		&ad.Fb,
-		ad.HuiCfg.Pager.Title,
-		termW)
-
-	common.DrawUpper(ad.ComCfg, headerLines, termW, titleLines)
-
-	contentHeight = termH -
-		len(common.SplitByLines(termW, ad.HuiCfg.Header)) -
-		1 -
-		len(common.SplitByLines(termW, curMenu.Title)) -
-		1
-	drawMenu(contentHeight, curMenu, *ad.MPath.curCursor(), ad.HuiCfg, termW)
-
-	csi.SetCursor(1, termH)
-	fmt.Printf("%v", lower)
-	csi.SetCursorAligned(ad.ComCfg.CmdLine.Alignment,
-		(len(ad.ComCfg.CmdLine.Prefix) + len(ad.CmdLine.Content)),
-		termW,
-		(len(ad.ComCfg.CmdLine.Prefix) + ad.CmdLine.Cursor + 1),
-		termH)
-
-	handleInput(contentHeight, cmdMap, fnMap, ad)
-}
-
 func main(
 ) {
 	var (
@@ -422,6 +891,16 @@ func main(
 		fnMap   common.ScriptFnMap
 	)
 
+	drawFn := func() {
+		draw(&ad)
+	}
+
+	keyFn := func(key string) {
+		handleKey(key, cmdMap, ad.ContentHeight, fnMap, &ad)
+	}
+
+	defer common.RecoverTerm()
+
 	ad.Active = handleArgs(&cfgPath)
 	if ad.Active == false {
 		return
@@ -432,28 +911,45 @@ func main(
 	ad.ComAppData = common.NewComAppData(cfgPath)
 	ad.MPath = make(menuPath, 1, 8)
 	ad.HuiCfg = huiConfigFromFile(&ad, cfgPath, fnMap)
+	ad.CmdDocs = getCmdDocs()
+	ad.FnDocs = getFnDocs()
+	ad.AddComCmds(cmdMap)
+	ad.AddConfigCmds(cmdMap,
+		common.MergeCommands(ad.ComCfg.Commands, ad.HuiCfg.Commands),
+		ad.HuiCfg.Pager.Title)
+	ad.AddHelpCmd(cmdMap,
+		fnMap,
+		HELP,
+		&ad.HuiCfg,
+		"hui.json",
+		ad.HuiCfg.Pager.Title)
 
 	_, mainMenuExists := ad.HuiCfg.Menus["main"]
 
 	if mainMenuExists == false {
 		panic(`"main" menu not found in config`)
 	}
-	ad.MPath[0] = menuPathNode{0, "main"}
+	ad.MPath[0] = menuPathNode{0, "main", 0}
 
 	if ad.HuiCfg.Events.Start != "" {
 		fnMap[ad.HuiCfg.Events.Start]()
 	}
 
-	fmt.Printf(csi.CursorHide)
-	defer fmt.Printf(csi.CursorShow)
-	defer fmt.Printf("%v%v\n", csi.FgDefault, csi.BgDefault)
+	ad.LoadMacros("hui.macros.json")
+	ad.SourceRcFile(cmdMap, "huirc", cfgPath)
 
-	for ad.Active {
-		tick(cmdMap, fnMap, &ad)
-	}
+	common.SetupTerm()
+	defer common.RestoreTerm()
+	ad.Events = common.NewEvents()
+	ad.Events.SetTicker(ad.ComCfg.Template.RefreshInterval())
+
+	ad.Run(drawFn, keyFn)
 
+	// the alternate screen is gone once the terminal is restored,
+	// so what the quit event leaves is printed after it
+	common.RestoreTerm()
 	if ad.HuiCfg.Events.Quit != "" {
 		fnMap[ad.HuiCfg.Events.Quit]()
-		tick(cmdMap, fnMap, &ad)
+		ad.PrintLower()
 	}
 }
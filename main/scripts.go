@@ -24,6 +24,24 @@ func getCmdMap(
 	}
 }
 
+func getCmdDocs(
+) common.ScriptDocMap {
+	return common.ScriptDocMap{
+		"sh":  "runs the given shell command and shows its output",
+		"shs": "runs the given shell command as an interactive session",
+	}
+}
+
+func getFnDocs(
+) common.ScriptDocMap {
+	return common.ScriptDocMap{
+		"Goodbye":             "disables input and leaves a goodbye",
+		"PutWordsIntoMyMouth": "opens the command line with a surprise",
+		"Quit":                "quits hui",
+		"Welcome":             "shows a multi-line welcome",
+	}
+}
+
 func getFnMap(
 	ad *appData,
 ) common.ScriptFnMap {
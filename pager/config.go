@@ -5,15 +5,14 @@ package main
 
 import (
 	"github.com/SchokiCoder/gohui/common"
-	"github.com/SchokiCoder/gohui/csi"
 
 	"fmt"
 )
 
 type contentConfig struct {
-	Alignment string
-	Fg        csi.FgColor
-	Bg        csi.BgColor
+	common.RegionConfig
+	Wrap       string
+	WrapIndent int
 }
 
 type eventsConfig struct {
@@ -26,10 +25,11 @@ type pagerConfig struct {
 }
 
 type couConfig struct {
-	Header  string
-	Pager   pagerConfig
-	Content contentConfig
-	Events  eventsConfig
+	Header   string
+	Commands []common.CommandConfig
+	Pager    pagerConfig
+	Content  contentConfig
+	Events   eventsConfig
 }
 
 func couConfigFromFile(
@@ -40,6 +40,9 @@ func couConfigFromFile(
 
 	common.AnyConfigFromFile(&ret, "courier.json", cfgPath)
 	ret.validateAlignments()
+	ret.validateWraps()
+	ret.Content.Validate()
+	common.ValidateCommands(ret.Commands)
 	if ret.Events.Start != "" {
 		validateGo(fnMap, ret.Events.Start)
 	}
@@ -55,6 +58,11 @@ func (c couConfig) validateAlignments(
 	common.ValidateAlignment(c.Content.Alignment)
 }
 
+func (c couConfig) validateWraps(
+) {
+	common.ValidateWrap(c.Content.Wrap, c.Content.WrapIndent)
+}
+
 func validateGo(
 	fnMap common.ScriptFnMap,
 	fnName string,
@@ -17,6 +17,8 @@ import (
 type appData struct {
 	common.ComAppData
 	Content           string
+	ContentHeight     int
+	ContentLineCount  int
 	CouCfg            couConfig
 	Scroll            int
 	Title             string
@@ -46,6 +48,9 @@ Options:
     -h --help
         prints this message then exits
 
+    --no-color
+        shows no colors, like when NO_COLOR is set
+
     -v --version
         prints version information then exits
 
@@ -63,6 +68,12 @@ Default keybinds:
     :
         enter the internal command line
 
+    Q *register*
+        record keys into the register, until Q is pressed again
+
+    @ *register*
+        play the keys recorded in the register
+
 Internal commands:
 
     q quit exit
@@ -71,6 +82,36 @@ Internal commands:
     *number*
         when given a positive number, it is used as a line number to scroll to
 
+    map [keys] [sequence]
+        maps keys to a key sequence (e.g. "map x :sh date<CR>"),
+        lists all mappings when given no arguments
+
+    unmap *keys*
+        removes a key mapping
+
+    source *file*
+        runs each line of the given file as an internal command
+
+    help [topic]
+        opens the help in the pager, optionally only the given topic
+
+    sh *command*
+        runs the given shell command and shows its output as feedback
+
+    shs *command*
+        runs the given shell command as an interactive session
+
+    Further commands can be declared via "Commands" in common.json and courier.json.
+
+Files:
+
+    courierrc
+        internal commands run on startup, one per line,
+        searched for in the same directories as the config files
+
+    $XDG_STATE_HOME/hui/ or ~/.local/state/hui/
+        recorded key macros are kept here
+
 Environmental variables:
 
     PAGER
@@ -80,27 +121,131 @@ Environmental variables:
         sets the title
 `
 
+func draw(
+	ad *appData,
+) {
+	var err error
+	var region = ad.CouCfg.Content.Region()
+	var termH, termW int
+
+	termW, termH, err = term.GetSize(int(os.Stdin.Fd()))
+	if err != nil {
+		panic(fmt.Sprintf("Could not get term size:\n%v", err))
+	}
+	ad.Scr.Resize(termW, termH)
+	ad.Scr.Clear()
+
+	ad.DrawLayout(ad.CouCfg.Header,
+		ad.Title,
+		ad.CouCfg.Pager.Title,
+		func(w int) int {
+			w -= ad.ComCfg.Scroll.BarWidth()
+			return region.Height(len(contentLines(w, *ad)))
+		},
+		func(scr *common.Screen) {
+			var (
+				barW = ad.ComCfg.Scroll.BarWidth()
+				lines = contentLines(scr.W - barW, *ad)
+				shown int
+			)
+
+			ad.ContentHeight = scr.H - region.VSpace()
+			if ad.ContentHeight < 1 {
+				ad.ContentHeight = 1
+			}
+			ad.ContentLineCount = len(lines)
+			ad.ComCfg.Scroll.Clamp(&ad.Scroll,
+				lineRows(len(lines)),
+				ad.ContentHeight)
+
+			shown = drawContent(scr.Sub(0, 0, scr.W - barW, scr.H),
+				0,
+				lines,
+				ad.ContentHeight,
+				*ad)
+			common.DrawScrollbar(scr,
+				ad.ComCfg,
+				region.TopSpace(),
+				ad.ContentHeight,
+				ad.Scroll,
+				shown,
+				len(lines))
+			ad.Ruler = ad.ComCfg.Scroll.PositionText(ad.Scroll,
+				shown,
+				len(lines))
+		})
+
+	ad.Scr.Flush()
+}
+
+func contentLines(
+	w int,
+	ad appData,
+) []string {
+	return common.WrapText(ad.CouCfg.Content.Region().InnerWidth(w),
+		ad.Content,
+		ad.CouCfg.Content.Wrap,
+		ad.CouCfg.Content.WrapIndent)
+}
+
+// drawContent returns how many lines are shown.
 func drawContent(
+	scr *common.Screen,
+	y int,
 	contentLines []string,
 	contentHeight int,
 	ad appData,
-	termW int,
-) {
+) int {
 	var (
-		drawRange int = ad.Scroll + contentHeight
+		above, below bool
+		lines []string
+		n int
+		rows = lineRows(len(contentLines))
+		scrollStyle = ad.ComCfg.Scroll.Style()
+		style = csi.Style{
+			Fg: ad.CouCfg.Content.Fg,
+			Bg: ad.CouCfg.Content.Bg,
+			Attrs: ad.CouCfg.Content.Attrs,
+		}
+		styles []csi.Style
 	)
 
-	if drawRange > len(contentLines) {
-		drawRange = len(contentLines)
+	n, above, below = ad.ComCfg.Scroll.Fit(rows, ad.Scroll, contentHeight)
+
+	if above {
+		lines = append(lines,
+			ad.ComCfg.Scroll.Marker(ad.ComCfg.Scroll.MarkerAbove, ad.Scroll))
+		styles = append(styles, scrollStyle)
 	}
 
-	for _, v := range contentLines[ad.Scroll:drawRange] {
-		common.Cprinta(ad.CouCfg.Content.Alignment,
-			ad.CouCfg.Content.Fg,
-			ad.CouCfg.Content.Bg,
-			termW,
-			v)
+	for _, v := range contentLines[ad.Scroll:ad.Scroll + n] {
+		lines = append(lines, v)
+		styles = append(styles, style)
 	}
+
+	if below {
+		lines = append(lines,
+			ad.ComCfg.Scroll.Marker(ad.ComCfg.Scroll.MarkerBelow,
+				len(contentLines) - ad.Scroll - n))
+		styles = append(styles, scrollStyle)
+	}
+
+	scr.PrintRegion(ad.CouCfg.Content.Region(), y, lines, styles)
+
+	return n
+}
+
+// lineRows returns the rows of n lines, for the scroll logic.
+func lineRows(
+	n int,
+) []int {
+	var ret = make([]int, n)
+
+	for i := range ret {
+		ret[i] = 1
+	}
+
+	return ret
 }
 
 func handleArgs(
@@ -141,6 +286,11 @@ func handleArgs(
 			fmt.Printf(HELP)
 			return "", false
 
+		case "--no-color":
+			// also for the programs started from here
+			os.Setenv("NO_COLOR", "1")
+			csi.ColorDepth = csi.ColorsNone
+
 		case "-v":
 			fallthrough
 		case "--version":
@@ -163,40 +313,6 @@ func handleArgs(
 	return filepath, true
 }
 
-func handleInput(
-	cmdMap common.ScriptCmdMap,
-	contentHeight int,
-	contentLineCount int,
-	ad *appData,
-) {
-	var (
-		canonicalState *term.State
-		err error
-		input string
-		rawInput = make([]byte, 4)
-		rawInputLen int
-	)
-
-	if ad.AcceptInput == false {
-		return
-	}
-
-	canonicalState, err = term.MakeRaw(int(os.Stdin.Fd()))
-	if err != nil {
-		panic(fmt.Sprintf("Switching to raw mode failed:\n%v", err))
-	}
-
-	rawInputLen, err = os.Stdin.Read(rawInput)
-	if err != nil {
-		panic(fmt.Sprintf("Reading from stdin failed:\n%v", err))
-	}
-	input = string(rawInput[0:rawInputLen])
-
-	term.Restore(int(os.Stdin.Fd()), canonicalState)
-
-	handleKey(string(input), cmdMap, contentHeight, contentLineCount, ad)
-}
-
 func handleKey(
 	key string,
 	cmdMap common.ScriptCmdMap,
@@ -232,7 +348,7 @@ func handleKey(
 
 	case ad.ComCfg.Keys.Cmdmode:
 		ad.CmdLine.Active = true
-		fmt.Printf(csi.CursorShow)
+		csi.Send(csi.CursorShow)
 
 	case csi.PgUp:
 		if ad.Scroll-contentHeight < 0 {
@@ -293,55 +409,6 @@ func readfile(
 	return string(ret)
 }
 
-func tick(
-	cmdMap common.ScriptCmdMap,
-	ad *appData,
-) {
-	var contentLines []string
-	var contentHeight int
-	var err error
-	var headerLines []string
-	var lower string
-	var termH, termW int
-	var titleLines []string
-
-	fmt.Print(csi.Clear)
-	termW, termH, err = term.GetSize(int(os.Stdin.Fd()))
-	if err != nil {
-		panic(fmt.Sprintf("Could not get term size:\n%v", err))
-	}
-
-	headerLines = common.SplitByLines(termW, ad.CouCfg.Header)
-	titleLines = common.SplitByLines(termW, ad.Title)
-	contentLines = common.SplitByLines(termW, ad.Content)
-	lower = common.GenerateLower(ad.CmdLine.Content,
-		ad.CmdLine.Active,
-		ad.ComCfg,
-		&ad.Fb,
-		ad.CouCfg.Pager.Title,
-		termW)
-
-	common.DrawUpper(ad.ComCfg, headerLines, termW, titleLines)
-
-	contentHeight = termH -
-		len(common.SplitByLines(termW, ad.CouCfg.Header)) -
-		1 -
-		len(common.SplitByLines(termW, ad.Title)) -
-		1
-
-	drawContent(contentLines, contentHeight, *ad, termW)
-
-	csi.SetCursor(1, termH)
-	fmt.Printf("%v", lower)
-	csi.SetCursorAligned(ad.ComCfg.CmdLine.Alignment,
-		(len(ad.ComCfg.CmdLine.Prefix) + len(ad.CmdLine.Content)),
-		termW,
-		(len(ad.ComCfg.CmdLine.Prefix) + ad.CmdLine.Cursor + 1),
-		termH)
-
-	handleInput(cmdMap, contentHeight, len(contentLines), ad)
-}
-
 func main(
 ) {
 	var (
@@ -352,6 +419,16 @@ func main(
 		fnMap    common.ScriptFnMap
 	)
 
+	drawFn := func() {
+		draw(&ad)
+	}
+
+	keyFn := func(key string) {
+		handleKey(key, cmdMap, ad.ContentHeight, ad.ContentLineCount, &ad)
+	}
+
+	defer common.RecoverTerm()
+
 	filepath, ad.Active = handleArgs(&cfgPath)
 	if ad.Active == false {
 		return
@@ -365,21 +442,38 @@ func main(
 	fnMap = getFnMap(&ad)
 	ad.ComAppData = common.NewComAppData(cfgPath)
 	ad.CouCfg = couConfigFromFile(cfgPath, fnMap)
+	ad.CmdDocs = getCmdDocs()
+	ad.FnDocs = getFnDocs()
+	ad.AddComCmds(cmdMap)
+	ad.AddConfigCmds(cmdMap,
+		common.MergeCommands(ad.ComCfg.Commands, ad.CouCfg.Commands),
+		ad.CouCfg.Pager.Title)
+	ad.AddHelpCmd(cmdMap,
+		fnMap,
+		HELP,
+		&ad.CouCfg,
+		"courier.json",
+		ad.CouCfg.Pager.Title)
 
 	if ad.CouCfg.Events.Start != "" {
 		fnMap[ad.CouCfg.Events.Start]()
 	}
 
-	fmt.Printf(csi.CursorHide)
-	defer fmt.Printf(csi.CursorShow)
-	defer fmt.Printf("%v%v\n", csi.FgDefault, csi.BgDefault)
+	ad.LoadMacros("courier.macros.json")
+	ad.SourceRcFile(cmdMap, "courierrc", cfgPath)
 
-	for ad.Active {
-		tick(cmdMap, &ad)
-	}
+	common.SetupTerm()
+	defer common.RestoreTerm()
+	ad.Events = common.NewEvents()
+	ad.Events.SetTicker(ad.ComCfg.Template.RefreshInterval())
+
+	ad.Run(drawFn, keyFn)
 
+	// the alternate screen is gone once the terminal is restored,
+	// so what the quit event leaves is printed after it
+	common.RestoreTerm()
 	if ad.CouCfg.Events.Quit != "" {
 		fnMap[ad.CouCfg.Events.Quit]()
-		tick(cmdMap, &ad)
+		ad.PrintLower()
 	}
 }
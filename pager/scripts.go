@@ -32,6 +32,22 @@ func getCmdMap(
 	}
 }
 
+func getCmdDocs(
+) common.ScriptDocMap {
+	return common.ScriptDocMap{
+		"sh":  "runs the given shell command and shows its output",
+		"shs": "runs the given shell command as an interactive session",
+	}
+}
+
+func getFnDocs(
+) common.ScriptDocMap {
+	return common.ScriptDocMap{
+		"Goodbye": "opens the command line with a goodbye",
+		"Welcome": "fills the command line with a welcome",
+	}
+}
+
 func getFnMap(
 	ad *appData,
 ) common.ScriptFnMap {